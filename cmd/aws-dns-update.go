@@ -24,6 +24,7 @@ import (
 	"os"
 	"strings"
 
+	"github.com/7onetella/morgan/internal/naming"
 	"github.com/7onetella/morgan/tools/awsapi/route53"
 	"github.com/aws/aws-sdk-go/aws"
 
@@ -31,17 +32,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var dnsUpdateCmdNaming namingFlags
+
 // dnsUpdateCmd represents the dns update command
 var dnsUpdateCmd = &cobra.Command{
-	Use:     "update <instance name> <A record name>",
-	Short:   "Updates dns record",
-	Long:    `Display dns record`,
+	Use:   "update <instance name> <A record name>",
+	Short: "Updates dns record",
+	Long: `Updates dns A record with private ip of the instance.
+
+A record name is validated against the naming policy in ~/.morgan.yaml. It can be generated from
+the policy pattern with --team, --app and --environment instead.`,
 	Example: `$`,
-	Args:    cobra.MinimumNArgs(2),
+	Args:    cobra.RangeArgs(1, 2),
 	Run: func(cmd *cobra.Command, args []string) {
 
 		instanceName := args[0]
-		dnsARecord := args[1]
+		dnsARecord := ""
+		if len(args) > 1 {
+			dnsARecord = args[1]
+		}
+		dnsARecord = ResolveName(naming.KindDNS, dnsARecord, dnsUpdateCmdNaming)
 
 		resp, err := ec2w.DescribeInstanceByNameTag(instanceName)
 		ExitOn(err)
@@ -79,4 +89,6 @@ var dnsUpdateCmd = &cobra.Command{
 
 func init() {
 	dnsCmd.AddCommand(dnsUpdateCmd)
+
+	addNamingFlags(dnsUpdateCmd.Flags(), &dnsUpdateCmdNaming)
 }
@@ -23,6 +23,7 @@ package cmd
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/7onetella/morgan/tools/awsapi/ec2w"
 	"github.com/olekukonko/tablewriter"
@@ -93,7 +94,9 @@ var ec2StartCmd = &cobra.Command{
 			return
 		}
 
+		started := time.Now()
 		resp, err := ec2w.StartInstances(instanceIDSlice)
+		NotifyInstances("start", instanceIDs, instanceIDSlice, started, err)
 		ExitOn(err)
 
 		table := tablewriter.NewWriter(os.Stdout)
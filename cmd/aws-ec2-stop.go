@@ -23,6 +23,7 @@ package cmd
 import (
 	"os"
 	"strings"
+	"time"
 
 	"github.com/7onetella/morgan/tools/awsapi/ec2w"
 	"github.com/olekukonko/tablewriter"
@@ -82,7 +83,9 @@ var ec2StopCmd = &cobra.Command{
 			return
 		}
 
+		started := time.Now()
 		resp, err := ec2w.StopInstances(instanceIDSlice)
+		NotifyInstances("stop", instanceIDs, instanceIDSlice, started, err)
 		ExitOn(err)
 
 		table := tablewriter.NewWriter(os.Stdout)
@@ -22,6 +22,7 @@ package cmd
 
 import (
 	"os"
+	"time"
 
 	"github.com/7onetella/morgan/tools/awsapi/ec2w"
 	"github.com/olekukonko/tablewriter"
@@ -47,7 +48,9 @@ var ec2TerminateCmd = &cobra.Command{
 			instanceIDSlice = append(instanceIDSlice, k)
 		}
 
+		started := time.Now()
 		resp, err := ec2w.TerminateInstances(instanceIDSlice)
+		NotifyInstances("terminate", instanceIDs, instanceIDSlice, started, err)
 		ExitOn(err)
 
 		table := tablewriter.NewWriter(os.Stdout)
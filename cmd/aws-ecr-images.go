@@ -0,0 +1,80 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecrImagesCmd = &cobra.Command{
+	Use:     "images <repository>",
+	Short:   "Lists images of ecr repository",
+	Long:    `Lists images of ecr repository, newest first`,
+	Example: "team/foo-svc",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Digest", "Tags", "Pushed", "Size (MiB)"})
+
+		for _, image := range DescribeImagesNewestFirst(args[0]) {
+			table.Append([]string{
+				shortDigest(aws.StringValue(image.ImageDigest)),
+				formatImageTags(image.ImageTags),
+				formatPushedAt(image.ImagePushedAt),
+				fmt.Sprintf("%.1f", float64(aws.Int64Value(image.ImageSizeInBytes))/1024/1024),
+			})
+		}
+
+		table.Render()
+
+	},
+}
+
+var ecrTagsCmd = &cobra.Command{
+	Use:     "tags <repository>",
+	Short:   "Lists tags of ecr repository",
+	Long:    `Lists tags of ecr repository, most recently pushed first. Output is one tag per line for scripting`,
+	Example: "team/foo-svc",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		for _, image := range DescribeImagesNewestFirst(args[0]) {
+			for _, tag := range image.ImageTags {
+				fmt.Println(tag)
+			}
+		}
+
+	},
+}
+
+func init() {
+
+	ecrCmd.AddCommand(ecrImagesCmd)
+
+	ecrCmd.AddCommand(ecrTagsCmd)
+
+}
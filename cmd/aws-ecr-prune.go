@@ -0,0 +1,169 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/imageref"
+	"github.com/7onetella/morgan/internal/retention"
+	"github.com/7onetella/morgan/tools/awsapi/ecrw"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecrPruneCmdKeep int
+var ecrPruneCmdKeepDeployed bool
+var ecrPruneCmdDryRun bool
+
+var ecrPruneCmd = &cobra.Command{
+	Use:   "prune <repository>",
+	Short: "Deletes old images of ecr repository",
+	Long: `Deletes images of ecr repository except the most recently pushed ones.
+
+With --keep-deployed, images used by task definitions of every deployment of every service in every
+cluster and of every task scheduled with ecs schedule are kept as well, whether referred to by tag or
+by digest. They do not count towards --keep.`,
+	Example: "team/foo-svc --keep 20 --keep-deployed --dry-run",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		repository := args[0]
+
+		protected := retention.Protected{}
+		if ecrPruneCmdKeepDeployed {
+			var err error
+			protected, err = DeployedImages(repository)
+			ExitOnError(err, "finding deployed images")
+		}
+
+		images := []retention.Image{}
+		for _, image := range DescribeImagesNewestFirst(repository) {
+			images = append(images, retentionImage(image))
+		}
+
+		deletes := retention.Select(images, ecrPruneCmdKeep, protected)
+		if len(deletes) == 0 {
+			Success(fmt.Sprintf("nothing to prune. %d images in %s", len(images), repository))
+			return
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Digest", "Tags", "Pushed"})
+		digests := []string{}
+		for _, image := range deletes {
+			table.Append([]string{shortDigest(image.Digest), formatImageTags(image.Tags), image.PushedAt.Local().Format("2006-01-02 15:04:05")})
+			digests = append(digests, image.Digest)
+		}
+		table.Render()
+
+		if ecrPruneCmdDryRun {
+			Info(fmt.Sprintf("%d of %d images would be deleted", len(deletes), len(images)))
+			return
+		}
+
+		failures, err := ecrw.BatchDeleteImages(repository, digests...)
+		ExitOnError(err, "deleting images")
+
+		for _, f := range failures {
+			Failure(fmt.Sprintf("deleting %s: %s", shortDigest(aws.StringValue(f.ImageId.ImageDigest)), aws.StringValue(f.FailureReason)))
+		}
+		if len(failures) > 0 {
+			os.Exit(1)
+		}
+
+		Success(fmt.Sprintf("deleting %d of %d images", len(deletes), len(images)))
+
+	},
+}
+
+func init() {
+
+	ecrCmd.AddCommand(ecrPruneCmd)
+
+	flags := ecrPruneCmd.Flags()
+
+	flags.IntVar(&ecrPruneCmdKeep, "keep", 20, "optional: number of most recently pushed images to keep")
+
+	flags.BoolVar(&ecrPruneCmdKeepDeployed, "keep-deployed", false, "optional: keeps images used by services")
+
+	flags.BoolVar(&ecrPruneCmdDryRun, "dry-run", false, "optional: shows images that would be deleted without deleting them")
+
+}
+
+// DeployedImages returns tags and digests of images of ecr repository used by any deployment of any service
+// or by any task morgan runs on schedule
+func DeployedImages(repository string) (retention.Protected, error) {
+	protected := retention.Protected{}
+
+	services, err := describeAllServices()
+	if err != nil {
+		return protected, err
+	}
+
+	taskdefs := map[string]bool{}
+	for _, s := range services {
+		for _, d := range s.Deployments {
+			taskdefs[aws.StringValue(d.TaskDefinition)] = true
+		}
+	}
+
+	rules, err := eventsw.ListRules()
+	if err != nil {
+		return protected, err
+	}
+	for _, rule := range rules {
+		if rule.ScheduleExpression == nil {
+			continue
+		}
+		target, err := findScheduleTarget(aws.StringValue(rule.Name))
+		if err != nil {
+			continue
+		}
+		taskdefs[aws.StringValue(target.EcsParameters.TaskDefinitionArn)] = true
+	}
+
+	for taskdef := range taskdefs {
+		result, err := ecsw.DescribeTaskDefinition(taskdef)
+		if err != nil {
+			return protected, err
+		}
+		for _, image := range taskDefinitionImages(result.TaskDefinition) {
+			ref, err := imageref.Parse(image)
+			if err != nil || !ref.IsECR() || strings.TrimPrefix(ref.Path, "/") != repository {
+				continue
+			}
+			if len(ref.Digest) > 0 {
+				protected[ref.Digest] = true
+			} else {
+				protected[ref.TagOrDefault()] = true
+			}
+			Debug("deployed " + image + " in " + parseTaskDefinitionStr(taskdef))
+		}
+	}
+
+	return protected, nil
+}
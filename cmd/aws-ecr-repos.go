@@ -0,0 +1,59 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecrw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecrReposCmd = &cobra.Command{
+	Use:     "repos",
+	Short:   "Lists ecr repositories",
+	Long:    `Lists ecr repositories`,
+	Aliases: []string{"repositories"},
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		repositories, err := ecrw.DescribeRepositories()
+		ExitOnError(err, "describing repositories")
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "URI", "Created"})
+
+		for _, r := range repositories {
+			table.Append([]string{aws.StringValue(r.RepositoryName), aws.StringValue(r.RepositoryUri), formatPushedAt(r.CreatedAt)})
+		}
+
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecrCmd.AddCommand(ecrReposCmd)
+
+}
@@ -0,0 +1,81 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/retention"
+	"github.com/7onetella/morgan/tools/awsapi/ecrw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	"github.com/spf13/cobra"
+)
+
+// ecrCmd represents the ecr command
+var ecrCmd = &cobra.Command{
+	Use:   "ecr",
+	Short: "Automation for ecr",
+	Long:  `Automation for ecr`,
+}
+
+func init() {
+	awsCmd.AddCommand(ecrCmd)
+}
+
+// DescribeImagesNewestFirst describes images of repository sorted by push time, newest first
+func DescribeImagesNewestFirst(repository string) []ecr.ImageDetail {
+	images, err := ecrw.DescribeImages(repository)
+	ExitOnError(err, "describing images of "+repository)
+
+	sort.SliceStable(images, func(i, j int) bool {
+		return aws.TimeValue(images[i].ImagePushedAt).After(aws.TimeValue(images[j].ImagePushedAt))
+	})
+
+	return images
+}
+
+// retentionImage converts ecr image for retention selection
+func retentionImage(image ecr.ImageDetail) retention.Image {
+	return retention.Image{
+		Digest:   aws.StringValue(image.ImageDigest),
+		Tags:     image.ImageTags,
+		PushedAt: aws.TimeValue(image.ImagePushedAt),
+	}
+}
+
+func formatPushedAt(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func formatImageTags(tags []string) string {
+	if len(tags) == 0 {
+		return "<untagged>"
+	}
+	sorted := append([]string{}, tags...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -21,8 +21,10 @@
 package cmd
 
 import (
+	"errors"
 	"strconv"
 
+	"github.com/7onetella/morgan/internal/naming"
 	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/aws/aws-sdk-go/aws"
 
@@ -37,6 +39,16 @@ var ecsCreateCmdEnvVars []string
 var ecsCreateCmdDesiredCount int64
 var ecsCreateCmdTimeout int64
 var ecsCreateCmdWaitForServiceStable bool
+var ecsCreateCmdPinDigest bool
+var ecsCreateCmdInsecureRegistry bool
+var ecsCreateCmdSkipCapacityCheck bool
+var ecsCreateCmdSkipImageCheck bool
+var ecsCreateCmdDeployment deploymentFlags
+var ecsCreateCmdPlacement placementFlags
+var ecsCreateCmdStorage storageFlags
+var ecsCreateCmdDiscovery discoveryFlags
+var ecsCreateCmdNaming namingFlags
+var ecsCreateCmdRoles roleFlags
 
 var ecsCreateCmd = &cobra.Command{
 	Use:   "create <service-name> <size> <port> <docker-image>",
@@ -47,28 +59,74 @@ You are expected to have some other mechanism to put your service behind reverse
 Consul and Fabio proxy can be used to attach standalone service such as our example.
 
 The value of <size> parameter is t-shirt sized.
-* xsmall  : CPU 63,   Memory 128
+* xsmall  : CPU 64,   Memory 128
 * small   : CPU 128,  Memory 256
 * medium  : CPU 256,  Memory 512
 * large   : CPU 512,  Memory 1024
 * xlarge  : CPU 1024, Memory 2048
-* 2xlarge : CPU 2024, Memory 4096`,
+* 2xlarge : CPU 2048, Memory 4096
+
+Sizes can be overridden or added in ~/.morgan.yaml. memoryReservation defaults to memory.
+
+ecs:
+  sizes:
+    jvm:
+      cpu: 1024
+      memory: 3072
+      memoryReservation: 2048
+      ulimits:
+        - name: nofile
+          soft: 65536
+          hard: 65536
+
+Service name, which is also used as task definition family and container name, is validated against
+the naming policy in ~/.morgan.yaml. The name can be generated from the policy pattern instead.
+* morgan aws ecs create small 8080 nginx:latest --team pay --app api --environment qa
+
+Unless --skip-capacity-check is specified, the cluster is checked for a container instance
+with enough cpu and memory left for the size before the service is created.
+
+Singleton services bound to a fixed host port should use --min-healthy-percent 0 --max-percent 100
+so that the old task is stopped before the new one is placed on the same host.
+Deployment circuit breaker is not supported by the aws sdk version morgan is built with.
+Use ecs rollback to go back to the previous task definition when a deployment fails.
+
+Placement is set when the service is created and can not be changed by ecs update.
+* --placement-constraint "attribute:ecs.instance-type =~ t3.*" --distinct-instance
+* --spread az --binpack memory
+
+Stateful services can keep data on the host or in a docker volume.
+* --volume data:/var/lib/redis --mount data:/data    : host path
+* --volume pgdata --mount pgdata:/var/lib/postgresql : docker volume, survives task restarts
+* --tmpfs /tmp:128                                   : 128 MiB tmpfs
+
+Services that call aws get a task role. The role is created with the ecs trust policy when it does not exist
+and --policy grants least privilege access to the named resources only. The grants are put in the inline
+policy <role>-policy, replacing it on existing roles. Without --task-role, the role is named <service>-task.
+* --policy s3-read:reports --policy sqs:jobs
+* --task-role foo-svc-role --execution-role ecsTaskExecutionRole`,
 	Example: `hello-world xsmall 8080 7onetealla/ref-api:latest \
 	--cluster Development \
 	-e NAME=web \
 	-e URLPREFIX=foo-svc.example.com/`,
 	Aliases: []string{"create-service"},
-	Args:    cobra.MinimumNArgs(4),
+	Args:    cobra.RangeArgs(3, 4),
 	Run: func(cmd *cobra.Command, args []string) {
 
+		// service name is omitted when it is generated from --team, --app and --environment
+		if len(args) == 3 {
+			args = append([]string{""}, args...)
+		}
+
 		cluster := ecsCreateCmdCluster
 		service := args[0]
 		if len(service) == 0 {
 			service = ecsCreateCmdService
 		}
+		service = ResolveName(naming.KindService, service, ecsCreateCmdNaming)
 		size := args[1]
 		port, _ := strconv.Atoi(args[2])
-		image := args[3]
+		image := ParseImage(args[3]).String()
 		taskdef := ecsCreateCmdTaskDefinition
 
 		// if cluster is not specified, then assume there is only one cluster and use that cluster
@@ -80,21 +138,94 @@ The value of <size> parameter is t-shirt sized.
 			cluster = GetClusterForService(clusters, service)
 		}
 
+		opts, err := ecsCreateCmdDeployment.options(cmd.Flags())
+		ExitOnError(err, "validating deployment options")
+
+		err = ecsCreateCmdPlacement.apply(&opts)
+		ExitOnError(err, "validating placement options")
+
+		if len(taskdef) > 0 && ecsCreateCmdRoles.isSet() {
+			ExitOn(errors.New("roles can not be set on existing task definition given by --task-definition"))
+		}
+
+		if len(taskdef) > 0 && ecsCreateCmdStorage.isSet() {
+			ExitOn(errors.New("volumes and mounts can not be added to existing task definition given by --task-definition"))
+		}
+
+		var sz Size
+		if len(taskdef) == 0 {
+			sz, err = GetSize(size)
+			ExitOn(err)
+
+			if !ecsCreateCmdSkipCapacityCheck {
+				err = CheckCapacity(cluster, size, sz)
+				ExitOn(err)
+			}
+		}
+
+		d := BeginDeployment("create", cluster, service)
+
 		envs := ConvertKeyValuePairArgSliceToMap(ecsCreateCmdEnvVars)
 
+		var td *ecs.TaskDefinition
 		if len(taskdef) == 0 {
-			cm := GetCPUAndMemory(size)
-			taskdef = RegisterNewTaskDefinition(cm.CPU, cm.Memory, int64(port), service, image, envs)
+			if !ecsCreateCmdSkipImageCheck {
+				err = VerifyImage(image)
+				ExitOn(err)
+			}
+			if ecsCreateCmdPinDigest {
+				image = PinImageDigest(image, ecsCreateCmdInsecureRegistry)
+			}
+			td = NewTaskDefinition(sz.CPU, sz.Memory, int64(port), service, image, envs)
+			sz.apply(&td.ContainerDefinitions[0])
+
+			err = ecsCreateCmdStorage.apply(td, &td.ContainerDefinitions[0])
+			ExitOnError(err, "configuring volumes")
+
+			d.Images = taskDefinitionImages(td)
 		}
 
-		_, err := ecsw.CreateService(cluster, service, taskdef, ecsCreateCmdDesiredCount)
+		// task definition registered by morgan uses default bridge networking and names the container after the service
+		networkMode := ecs.NetworkModeBridge
+		containerName := service
+		if len(taskdef) > 0 {
+			result, err := ecsw.DescribeTaskDefinition(taskdef)
+			ExitOnError(err, "describing task definition")
+			taskdef = *result.TaskDefinition.TaskDefinitionArn
+			networkMode = result.TaskDefinition.NetworkMode
+			if len(ecsCreateCmdDiscovery.namespace) > 0 && networkMode != ecs.NetworkModeAwsvpc {
+				containerName, err = PortContainerName(result.TaskDefinition, int64(port))
+				ExitOn(err)
+			}
+		}
+
+		// as with update, the task definition is registered before pre hooks so that hooks see its arn
+		if td != nil {
+			err = ecsCreateCmdRoles.apply(td, service)
+			ExitOn(err)
+
+			taskdef = RegisterTaskDefinition(td)
+		}
+		d.After(taskdef, ecsCreateCmdDesiredCount)
+
+		err = d.Pre()
+		ExitOn(err)
+
+		if len(ecsCreateCmdDiscovery.namespace) > 0 {
+			opts.ServiceRegistries = ecsCreateCmdDiscovery.serviceRegistries(service, containerName, int64(port), networkMode)
+		}
+
+		_, err = ecsw.CreateService(cluster, service, taskdef, ecsCreateCmdDesiredCount, opts)
 		ExitOnError(err, "creating service")
+		d.Changed()
 
 		if ecsCreateCmdWaitForServiceStable {
 			err = ecsw.ServiceStable(cluster, service, ecsCreateCmdTimeout)
 			ExitOnError(err, "service stable")
 		}
 
+		d.Finish(nil)
+
 		Success("creating service")
 
 	},
@@ -120,10 +251,47 @@ func init() {
 
 	flags.StringSliceVarP(&ecsCreateCmdEnvVars, "env", "e", []string{}, "optional: environment variables. e.g. -e key=value")
 
+	flags.BoolVar(&ecsCreateCmdPinDigest, "pin-digest", false, "optional: resolves docker tag to immutable digest before registering task definition")
+
+	flags.BoolVar(&ecsCreateCmdInsecureRegistry, "insecure-registry", false, "optional: uses http to talk to docker registry when resolving digest")
+
+	flags.BoolVar(&ecsCreateCmdSkipImageCheck, "skip-image-check", false, "optional: skips checking that ecr image exists")
+
+	flags.BoolVar(&ecsCreateCmdSkipCapacityCheck, "skip-capacity-check", false, "optional: skips checking that a container instance can fit the size")
+
+	addDeploymentFlags(flags, &ecsCreateCmdDeployment, false)
+
+	addPlacementFlags(flags, &ecsCreateCmdPlacement)
+
+	addDiscoveryFlags(flags, &ecsCreateCmdDiscovery)
+
+	addNamingFlags(flags, &ecsCreateCmdNaming)
+
+	addRoleFlags(flags, &ecsCreateCmdRoles)
+
+	flags.StringArrayVar(&ecsCreateCmdStorage.volumes, "volume", []string{}, "optional: host volume name:/host/path or docker volume name")
+
+	flags.StringArrayVar(&ecsCreateCmdStorage.mounts, "mount", []string{}, "optional: mounts volume name:/container/path[:ro]")
+
+	flags.StringArrayVar(&ecsCreateCmdStorage.tmpfs, "tmpfs", []string{}, "optional: tmpfs mount /container/path[:size in MiB]")
+
 }
 
 // RegisterNewTaskDefinition registers task definition
 func RegisterNewTaskDefinition(cpu, memory, port int64, service, image string, environmentVars map[string]string) string {
+	return RegisterTaskDefinition(NewTaskDefinition(cpu, memory, port, service, image, environmentVars))
+}
+
+// RegisterTaskDefinition registers task definition and returns its arn
+func RegisterTaskDefinition(taskdefinition *ecs.TaskDefinition) string {
+	result, err := ecsw.RegisterTaskDefinition(taskdefinition)
+	ExitOnError(err, "registering task definition")
+
+	return *result.TaskDefinition.TaskDefinitionArn
+}
+
+// NewTaskDefinition returns minimal task definition with single container named after the service
+func NewTaskDefinition(cpu, memory, port int64, service, image string, environmentVars map[string]string) *ecs.TaskDefinition {
 	envs := []ecs.KeyValuePair{}
 
 	for k, v := range environmentVars {
@@ -154,28 +322,5 @@ func RegisterNewTaskDefinition(cpu, memory, port int64, service, image string, e
 		Family: aws.String(service),
 	}
 
-	result, err := ecsw.RegisterTaskDefinition(taskdefinition)
-	ExitOnError(err, "registering task definition")
-
-	return *result.TaskDefinition.TaskDefinitionArn
-}
-
-// CPUAndMemory struct consisting of cpu and memory
-type CPUAndMemory struct {
-	CPU    int64
-	Memory int64
-}
-
-// GetCPUAndMemory returns GetCPUAndMemory based on given size
-func GetCPUAndMemory(size string) CPUAndMemory {
-	sizes := map[string]CPUAndMemory{
-		"xsmall":  CPUAndMemory{64, 128},
-		"small":   CPUAndMemory{128, 256},
-		"medium":  CPUAndMemory{256, 512},
-		"large":   CPUAndMemory{512, 1024},
-		"xlarge":  CPUAndMemory{1024, 2048},
-		"2xlarge": CPUAndMemory{1028, 4096},
-	}
-
-	return sizes[size]
+	return taskdefinition
 }
@@ -21,11 +21,14 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/7onetella/morgan/tools/awsapi/ecsw"
 	"github.com/spf13/cobra"
 )
 
 var ecsDeleteCmdCluster string
+var ecsDeleteCmdTimeout int64
 
 var ecsDeleteCmd = &cobra.Command{
 	Use:     "delete <service name>",
@@ -48,19 +51,39 @@ var ecsDeleteCmd = &cobra.Command{
 			cluster = GetClusterForService(clusters, service)
 		}
 
+		d := BeginDeployment("delete", cluster, service)
+
 		result, err := ecsw.DescribeServices(cluster, service)
 		ExitOnError(err, "describing services")
+		if len(result.Services) == 0 {
+			ExitOnError(errors.New("search result count 0"), "finding service")
+		}
+		d.Before(result.Services[0])
 		taskdef := *result.Services[0].TaskDefinition
 
 		result2, err := ecsw.DescribeTaskDefinition(taskdef)
 		ExitOnError(err, "describing task definition")
 
+		d.After(taskdef, 0)
+		d.Images = taskDefinitionImages(result2.TaskDefinition)
+
+		err = d.Pre()
+		ExitOn(err)
+
 		_, err = ecsw.UpdateService(cluster, service, *result2.TaskDefinition.TaskDefinitionArn, 0)
 		ExitOnError(err, "updating service")
+		d.Changed()
 
 		_, err = ecsw.DeleteService(cluster, service)
 		ExitOnError(err, "deleting services")
 
+		d.Finish(nil)
+
+		// clean up cloud map services created by ecs create --discovery-namespace
+		if len(result.Services[0].ServiceRegistries) > 0 {
+			DeleteServiceRegistries(result.Services[0], ecsDeleteCmdTimeout)
+		}
+
 		Success("deleting service")
 
 	},
@@ -74,4 +97,6 @@ func init() {
 
 	flags.StringVarP(&ecsDeleteCmdCluster, "cluster", "c", "", "ecs cluster")
 
+	flags.Int64Var(&ecsDeleteCmdTimeout, "timeout", 120, "timeout for cloud map instances to be deregistered")
+
 }
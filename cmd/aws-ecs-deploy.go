@@ -0,0 +1,201 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/7onetella/morgan/internal/naming"
+	"github.com/7onetella/morgan/internal/servicedef"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var ecsDeployCmdTimeout int64
+var ecsDeployCmdWaitForServiceStable bool
+var ecsDeployCmdSkipCapacityCheck bool
+var ecsDeployCmdSkipImageCheck bool
+var ecsDeployCmdDefinition definitionFlags
+
+var ecsDeployCmd = &cobra.Command{
+	Use:   "deploy <service>",
+	Short: "Deploys ecs service from definition files",
+	Long: `Deploys ecs service from base definition merged with the overlay of the environment.
+
+Definitions are kept in a directory per service. The base is named base.yaml and each overlay is named
+after its environment. Overlays can only set the fields the base knows about and env is merged key by key.
+
+deploy/foo-svc/base.yaml
+  image: 7onetella/foo-svc:{{ .Vars.version }}
+  size: small
+  port: 8080
+  env:
+    URLPREFIX: foo-svc.{{ .Env }}.example.com/
+
+deploy/foo-svc/stage.yaml
+  cluster: stage-cluster
+  size: large
+  desiredCount: 3
+  env:
+    LOG_LEVEL: warn
+
+Fields are service, cluster, image, size, port, desiredCount, minHealthyPercent, maxPercent and env.
+Files are go templates with .Service, .Env and .Vars set by --var. {{ env "NAME" }} reads environment variables.
+
+The service is created if it does not exist in the cluster, otherwise it is updated with a new task definition.
+Either way the deployment is recorded, hooked and notified as ecs create or ecs update.
+Use render to preview the result.`,
+	Example: "foo-svc --env stage --var version=1.0.0",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		def, err := ecsDeployCmdDefinition.load(args[0])
+		ExitOn(err)
+
+		sz, err := GetSize(def.Size)
+		ExitOn(err)
+
+		td := DefinitionTaskDefinition(def, sz)
+
+		opts := ecsw.ServiceOptions{
+			MinimumHealthyPercent: def.MinHealthyPercent,
+			MaximumPercent:        def.MaxPercent,
+		}
+
+		result, err := ecsw.DescribeServices(def.Cluster, def.Service)
+		ExitOnError(err, "describing services")
+		exists := len(result.Services) > 0 && *result.Services[0].Status != "INACTIVE"
+
+		// new services go through the same checks as ecs create, existing ones the same as ecs update
+		if !exists {
+			err = NamingPolicy().Validate(naming.KindService, def.Service)
+			ExitOn(err)
+		}
+
+		if !exists && !ecsDeployCmdSkipCapacityCheck {
+			err = CheckCapacity(def.Cluster, def.Size, sz)
+			ExitOn(err)
+		}
+
+		if !ecsDeployCmdSkipImageCheck {
+			err = VerifyImage(*td.ContainerDefinitions[0].Image)
+			ExitOn(err)
+		}
+
+		action := "create"
+		if exists {
+			action = "update"
+		}
+		d := BeginDeployment(action, def.Cluster, def.Service)
+
+		desiredCount := int64(1)
+		if exists {
+			d.Before(result.Services[0])
+			desiredCount = *result.Services[0].DesiredCount
+		}
+		if def.DesiredCount != nil {
+			desiredCount = *def.DesiredCount
+		}
+
+		taskdef := RegisterTaskDefinition(td)
+		d.After(taskdef, desiredCount)
+		d.Images = taskDefinitionImages(td)
+
+		err = d.Pre()
+		ExitOn(err)
+
+		if exists {
+			_, err = ecsw.UpdateServiceWithOptions(def.Cluster, def.Service, taskdef, desiredCount, opts)
+			ExitOnError(err, "updating service")
+			d.Changed()
+		} else {
+			_, err = ecsw.CreateService(def.Cluster, def.Service, taskdef, desiredCount, opts)
+			ExitOnError(err, "creating service")
+			d.Changed()
+		}
+
+		if ecsDeployCmdWaitForServiceStable {
+			err = ecsw.ServiceStable(def.Cluster, def.Service, ecsDeployCmdTimeout)
+			ExitOnError(err, "service stable")
+		}
+
+		d.Finish(nil)
+
+		Success("deploying " + def.Service + " to " + def.Cluster)
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsDeployCmd)
+
+	flags := ecsDeployCmd.Flags()
+
+	addDefinitionFlags(flags, &ecsDeployCmdDefinition)
+
+	flags.BoolVarP(&ecsDeployCmdWaitForServiceStable, "service-stable", "w", false, "optional: waits for service to become stable")
+
+	flags.Int64Var(&ecsDeployCmdTimeout, "timeout", 300, "optional: timeout for service stable")
+
+	flags.BoolVar(&ecsDeployCmdSkipImageCheck, "skip-image-check", false, "optional: skips checking that ecr image exists")
+
+	flags.BoolVar(&ecsDeployCmdSkipCapacityCheck, "skip-capacity-check", false, "optional: skips checking that a container instance can fit the size when service is created")
+
+}
+
+// definitionFlags selects service definition files and the environment overlay
+type definitionFlags struct {
+	dir  string
+	env  string
+	vars []string
+}
+
+func addDefinitionFlags(flags *pflag.FlagSet, f *definitionFlags) {
+
+	flags.StringVar(&f.dir, "dir", "", "optional: directory of service definitions. defaults to deploy.dir in config or ./deploy")
+
+	flags.StringVar(&f.env, "env", "", "optional: environment overlay to merge on top of base definition. e.g. stage")
+
+	flags.StringArrayVar(&f.vars, "var", []string{}, "optional: template variables. e.g. --var version=1.0.0")
+
+}
+
+func (f definitionFlags) load(service string) (servicedef.Definition, error) {
+	dir := f.dir
+	if len(dir) == 0 {
+		dir = viper.GetString("deploy.dir")
+	}
+	if len(dir) == 0 {
+		dir = "deploy"
+	}
+
+	return servicedef.Load(dir, service, f.env, ConvertKeyValuePairArgSliceToMap(f.vars))
+}
+
+// DefinitionTaskDefinition builds the task definition the service definition deploys
+func DefinitionTaskDefinition(def servicedef.Definition, sz Size) *ecs.TaskDefinition {
+	td := NewTaskDefinition(sz.CPU, sz.Memory, def.Port, def.Service, ParseImage(def.Image).String(), def.Env)
+	sz.apply(&td.ContainerDefinitions[0])
+
+	return td
+}
@@ -0,0 +1,89 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/pflag"
+)
+
+// deploymentFlags holds flags controlling how ecs rolls out new task definitions
+type deploymentFlags struct {
+	minHealthyPercent      int64
+	maxPercent             int64
+	healthCheckGracePeriod int64
+	forceNewDeployment     bool
+}
+
+// addDeploymentFlags adds deployment flags. --force-new-deployment only makes sense for existing services
+func addDeploymentFlags(flags *pflag.FlagSet, d *deploymentFlags, existingService bool) {
+
+	flags.Int64Var(&d.minHealthyPercent, "min-healthy-percent", 100, "optional: minimum healthy percent during deployment. use 0 with --max-percent 100 for singleton services")
+
+	flags.Int64Var(&d.maxPercent, "max-percent", 200, "optional: maximum percent of running tasks during deployment")
+
+	flags.Int64Var(&d.healthCheckGracePeriod, "health-check-grace-period", 0, "optional: seconds to ignore load balancer health checks after task starts")
+
+	if existingService {
+		flags.BoolVar(&d.forceNewDeployment, "force-new-deployment", false, "optional: forces new deployment even if task definition is unchanged")
+	}
+
+}
+
+// options converts flags to service options. only the flags explicitly specified are sent to aws
+func (d *deploymentFlags) options(flags *pflag.FlagSet) (ecsw.ServiceOptions, error) {
+	opts := ecsw.ServiceOptions{
+		ForceNewDeployment: d.forceNewDeployment,
+	}
+
+	if d.minHealthyPercent < 0 || d.minHealthyPercent > 100 {
+		return opts, fmt.Errorf("--min-healthy-percent must be between 0 and 100 but was %d", d.minHealthyPercent)
+	}
+
+	if d.maxPercent < 100 || d.maxPercent < d.minHealthyPercent {
+		return opts, fmt.Errorf("--max-percent must be at least 100 but was %d", d.maxPercent)
+	}
+
+	if flags.Changed("min-healthy-percent") || flags.Changed("max-percent") {
+		opts.MinimumHealthyPercent = aws.Int64(d.minHealthyPercent)
+		opts.MaximumPercent = aws.Int64(d.maxPercent)
+	}
+
+	if flags.Changed("health-check-grace-period") {
+		opts.HealthCheckGracePeriod = aws.Int64(d.healthCheckGracePeriod)
+	}
+
+	return opts, nil
+}
+
+// formatDeploymentConfiguration formats deployment configuration as min healthy/max percent. e.g. 0/100
+func formatDeploymentConfiguration(s ecs.Service) string {
+	dc := s.DeploymentConfiguration
+	if dc == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%d/%d", aws.Int64Value(dc.MinimumHealthyPercent), aws.Int64Value(dc.MaximumPercent))
+}
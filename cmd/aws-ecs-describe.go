@@ -22,118 +22,376 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/7onetella/morgan/internal/imageref"
+	"github.com/7onetella/morgan/internal/rightsize"
 	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 var ecsDescribeCmdCluster string
 var ecsDescribeCmdTimeout int64
+var ecsDescribeCmdWatch time.Duration
+var ecsDescribeCmdUnhealthy bool
+var ecsDescribeCmdName string
+var ecsDescribeCmdImageTag string
+var ecsDescribeCmdColumns []string
+var ecsDescribeCmdSort string
+
+var defaultDescribeColumns = []string{"cluster", "name", "pending", "running", "desired", "taskdef", "tags", "digests", "deployment", "deployments"}
 
 var ecsDescribeCmd = &cobra.Command{
-	Use:     "describe <service names>",
-	Short:   "Describes ecs",
-	Long:    `Describes ecs`,
-	Example: "foo-svc 1.0.0 --cluster api-cluster",
+	Use:   "describe <service names>",
+	Short: "Describes ecs",
+	Long: `Describes ecs services. Without service names, all services of all clusters are described.
+
+Columns are selected with --columns and sorted with --sort. Prefix sort column with - to sort in descending order.
+* cluster, name, pending, running, desired, taskdef, tags, digests, deployment, deployments (default)
+* launch-type, last-event, created-at, size
+
+--watch refreshes the view every 5 seconds. Use --watch=10s for another interval. Api errors during a watch are shown above the view and retried on the next refresh.
+--unhealthy only shows services that are not running the desired count. Rollout state of deployments
+is not shown since the aws sdk version morgan is built with does not return it.`,
+	Example: "foo-svc 1.0.0 --cluster api-cluster\n--unhealthy --watch --columns cluster,name,running,desired,last-event --sort -desired",
 	Aliases: []string{"describe-services"},
 	Args:    cobra.MinimumNArgs(0),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		specifiedCluster := ecsDescribeCmdCluster
-		clusterMembers := map[string][]string{}
-
-		isClusterSpecified := len(specifiedCluster) > 0
-		isServicesSpecified := len(args) > 0
-
-		// if no services are specified then get all services from all clusters
-		if !isServicesSpecified {
-			var clusters []string
-			if !isClusterSpecified {
-				result, err := ecsw.ListClusters()
-				ExitOnError(err, "listing clusters")
-				for _, clusterARN := range result.ClusterArns {
-					slashIndex := strings.LastIndex(clusterARN, "/")
-					cluster := clusterARN[slashIndex+1:]
-					clusters = append(clusters, cluster)
+		columns, err := describeColumnsFor(ecsDescribeCmdColumns, ecsDescribeCmdSort)
+		ExitOn(err)
+
+		var nameRegexp *regexp.Regexp
+		if len(ecsDescribeCmdName) > 0 {
+			nameRegexp, err = regexp.Compile(ecsDescribeCmdName)
+			ExitOn(err)
+		}
+
+		cache := newTaskDefinitionCache()
+
+		// in watch mode rows from the last successful tick are kept on error
+		rows := []describeRow{}
+
+		for {
+			current, err := describeAll(ecsDescribeCmdCluster, args, cache)
+			if ecsDescribeCmdWatch <= 0 {
+				ExitOn(err)
+			}
+			if err == nil {
+				rows = filterDescribeRows(current, nameRegexp, ecsDescribeCmdUnhealthy, ecsDescribeCmdImageTag)
+				sortDescribeRows(rows, ecsDescribeCmdSort)
+			}
+
+			if ecsDescribeCmdWatch > 0 {
+				// clear screen and move cursor to top left
+				fmt.Print("\033[H\033[2J")
+				fmt.Printf("every %s: %s   %d services\n", ecsDescribeCmdWatch, time.Now().Format("2006-01-02 15:04:05"), len(rows))
+				if err != nil {
+					// keep watching, the next tick retries
+					fmt.Println(red(xmark + err.Error()))
 				}
-			} else {
-				// if cluster is specified, add specified cluster to clusters
-				clusters = []string{specifiedCluster}
+				fmt.Println()
+			}
+
+			table := tablewriter.NewWriter(os.Stdout)
+			headers := []string{}
+			for _, c := range columns {
+				headers = append(headers, c.header)
 			}
+			table.SetHeader(headers)
 
-			for _, cluster := range clusters {
-				result, err := ecsw.ListServices(cluster)
-				ExitOnError(err, "listing services")
-				serviceARNs := result.ServiceArns
-				if len(serviceARNs) == 0 {
-					continue
+			for _, r := range rows {
+				values := []string{}
+				for _, c := range columns {
+					values = append(values, c.value(r))
 				}
-				clusterMembers[cluster] = serviceARNs
+				table.Append(values)
+			}
+
+			table.Render()
+
+			if ecsDescribeCmdWatch <= 0 {
+				return
 			}
+			time.Sleep(ecsDescribeCmdWatch)
 		}
 
-		if isServicesSpecified {
-			if !isClusterSpecified {
-				for _, service := range args[0:] {
-					clustersForSvc, err := ecsw.GetClustersForService(service)
-					ExitOnError(err, "getting clusters for service")
-					for cluster := range clustersForSvc {
-						// pull services for cluster
-						currServices := clusterMembers[cluster]
-						// if current services does not contain service
-						var isServiceFound bool
-						for _, serivceName := range currServices {
-							if serivceName == service {
-								isServiceFound = true
-								break
-							}
-						}
-						if !isServiceFound {
-							currServices = append(currServices, service)
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsDescribeCmd)
+
+	flags := ecsDescribeCmd.Flags()
+
+	flags.StringVar(&ecsDescribeCmdCluster, "cluster", "", "ecs cluster")
+
+	flags.DurationVarP(&ecsDescribeCmdWatch, "watch", "w", 0, "optional: refreshes the view. e.g. --watch or --watch=10s")
+	flags.Lookup("watch").NoOptDefVal = "5s"
+
+	flags.BoolVar(&ecsDescribeCmdUnhealthy, "unhealthy", false, "optional: shows only services not running desired count")
+
+	flags.StringVar(&ecsDescribeCmdName, "name", "", "optional: shows only services whose name matches regular expression")
+
+	flags.StringVar(&ecsDescribeCmdImageTag, "image-tag", "", "optional: shows only services running image with tag")
+
+	flags.StringSliceVar(&ecsDescribeCmdColumns, "columns", defaultDescribeColumns, "optional: columns to show")
+
+	flags.StringVar(&ecsDescribeCmdSort, "sort", "", "optional: column to sort by. e.g. name or -running")
+
+}
+
+// describeRow is a service and the task definition it runs
+type describeRow struct {
+	cluster string
+	service ecs.Service
+	td      *ecs.TaskDefinition
+}
+
+// describeColumn renders a column of describe table
+type describeColumn struct {
+	header string
+	value  func(r describeRow) string
+}
+
+var describeColumns = map[string]describeColumn{
+	"cluster": {"Cluster", func(r describeRow) string { return r.cluster }},
+	"name":    {"Name", func(r describeRow) string { return *r.service.ServiceName }},
+	"pending": {"Pending", func(r describeRow) string { return toString(r.service.PendingCount) }},
+	"running": {"Running", func(r describeRow) string { return toString(r.service.RunningCount) }},
+	"desired": {"Desired", func(r describeRow) string { return toString(r.service.DesiredCount) }},
+	"taskdef": {"TaskDef", func(r describeRow) string { return parseTaskDefinitionStr(*r.service.TaskDefinition) }},
+	"tags": {"Tags", func(r describeRow) string {
+		tags, _ := getTags(r.td)
+		return strings.Join(tags, ",")
+	}},
+	"digests": {"Digests", func(r describeRow) string {
+		_, digests := getTags(r.td)
+		return joinDigests(digests)
+	}},
+	"deployment":  {"Deployment", func(r describeRow) string { return formatDeploymentConfiguration(r.service) }},
+	"launch-type": {"Launch Type", func(r describeRow) string { return string(r.service.LaunchType) }},
+	"deployments": {"Deployments", func(r describeRow) string { return formatDeployments(r.service) }},
+	"last-event":  {"Last Event", func(r describeRow) string { return formatLastEvent(r.service) }},
+	"created-at": {"Created At", func(r describeRow) string {
+		if r.service.CreatedAt == nil {
+			return ""
+		}
+		return r.service.CreatedAt.Local().Format("2006-01-02 15:04:05")
+	}},
+	"size": {"Size", formatSizeName},
+}
+
+// describeColumnsFor validates column names and the sort column
+func describeColumnsFor(names []string, sortBy string) ([]describeColumn, error) {
+	valid := []string{}
+	for name := range describeColumns {
+		valid = append(valid, name)
+	}
+	sort.Strings(valid)
+
+	columns := []describeColumn{}
+	for _, name := range names {
+		c, ok := describeColumns[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %s. valid columns are %s", name, strings.Join(valid, ","))
+		}
+		columns = append(columns, c)
+	}
+
+	if _, ok := describeColumns[strings.TrimPrefix(sortBy, "-")]; len(sortBy) > 0 && !ok {
+		return nil, fmt.Errorf("unknown sort column %s. valid columns are %s", sortBy, strings.Join(valid, ","))
+	}
+
+	return columns, nil
+}
+
+// describeAll describes the services of args, or every service, in the specified cluster or all clusters
+func describeAll(specifiedCluster string, args []string, cache *taskDefinitionCache) ([]describeRow, error) {
+	clusterMembers, err := describeClusterMembers(specifiedCluster, args)
+	if err != nil {
+		return nil, err
+	}
+	return describeRows(clusterMembers, cache)
+}
+
+// describeClusterMembers returns services to describe by cluster
+func describeClusterMembers(specifiedCluster string, args []string) (map[string][]string, error) {
+	clusterMembers := map[string][]string{}
+
+	isClusterSpecified := len(specifiedCluster) > 0
+	isServicesSpecified := len(args) > 0
+
+	// if no services are specified then get all services from all clusters
+	if !isServicesSpecified {
+		var clusters []string
+		if !isClusterSpecified {
+			result, err := ecsw.ListClusters()
+			if err != nil {
+				return nil, fmt.Errorf("listing clusters: %v", err)
+			}
+			for _, clusterARN := range result.ClusterArns {
+				slashIndex := strings.LastIndex(clusterARN, "/")
+				cluster := clusterARN[slashIndex+1:]
+				clusters = append(clusters, cluster)
+			}
+		} else {
+			// if cluster is specified, add specified cluster to clusters
+			clusters = []string{specifiedCluster}
+		}
+
+		for _, cluster := range clusters {
+			serviceARNs, err := ecsw.ListAllServices(cluster)
+			if err != nil {
+				return nil, fmt.Errorf("listing services: %v", err)
+			}
+			if len(serviceARNs) == 0 {
+				continue
+			}
+			clusterMembers[cluster] = serviceARNs
+		}
+	}
+
+	if isServicesSpecified {
+		if !isClusterSpecified {
+			for _, service := range args[0:] {
+				clustersForSvc, err := ecsw.GetClustersForService(service)
+				if err != nil {
+					return nil, fmt.Errorf("getting clusters for service: %v", err)
+				}
+				for cluster := range clustersForSvc {
+					// pull services for cluster
+					currServices := clusterMembers[cluster]
+					// if current services does not contain service
+					var isServiceFound bool
+					for _, serivceName := range currServices {
+						if serivceName == service {
+							isServiceFound = true
+							break
 						}
-						// put modified services back into clusterMembers
-						clusterMembers[cluster] = currServices
 					}
+					if !isServiceFound {
+						currServices = append(currServices, service)
+					}
+					// put modified services back into clusterMembers
+					clusterMembers[cluster] = currServices
 				}
-			} else {
-				clusterMembers[specifiedCluster] = args[0:]
 			}
+		} else {
+			clusterMembers[specifiedCluster] = args[0:]
 		}
+	}
 
-		table := tablewriter.NewWriter(os.Stdout)
-		table.SetHeader([]string{"Cluster", "Name", "Pending", "Running", "Desired", "TaskDef", "Tags"})
+	return clusterMembers, nil
+}
+
+// describeRows describes services 10 at a time, the most describe services accepts, and their task definitions
+func describeRows(clusterMembers map[string][]string, cache *taskDefinitionCache) ([]describeRow, error) {
+	rows := []describeRow{}
+
+	for cluster, services := range clusterMembers {
+		for start := 0; start < len(services); start += 10 {
+			end := start + 10
+			if end > len(services) {
+				end = len(services)
+			}
 
-		for cluster, serviceARNS := range clusterMembers {
-			result, err := ecsw.DescribeServices(cluster, serviceARNS...)
-			ExitOnError(err, "describing services")
+			result, err := ecsw.DescribeServices(cluster, services[start:end]...)
+			if err != nil {
+				return nil, fmt.Errorf("describing services: %v", err)
+			}
 			if len(result.Services) == 0 {
-				ExitOnError(errors.New("search result count 0"), "finding services")
+				return nil, errors.New("finding services: search result count 0")
 			}
 
 			for _, s := range result.Services {
-				taskdef := parseTaskDefinitionStr(*s.TaskDefinition)
-				tags := getTags(taskdef)
-				table.Append([]string{cluster, *s.ServiceName, toString(s.PendingCount), toString(s.RunningCount), toString(s.DesiredCount), taskdef, strings.Join(tags, ",")})
+				rows = append(rows, describeRow{cluster: cluster, service: s})
 			}
 		}
+	}
+
+	taskdefs := []string{}
+	for _, r := range rows {
+		taskdefs = append(taskdefs, *r.service.TaskDefinition)
+	}
+	cache.Prefetch(taskdefs, 10)
 
-		table.Render()
+	for i := range rows {
+		rows[i].td = cache.Get(*rows[i].service.TaskDefinition)
+	}
 
-	},
+	return rows, nil
 }
 
-func init() {
+// filterDescribeRows keeps rows matching every filter that is set
+func filterDescribeRows(rows []describeRow, name *regexp.Regexp, unhealthy bool, imageTag string) []describeRow {
+	filtered := []describeRow{}
 
-	ecsCmd.AddCommand(ecsDescribeCmd)
+	for _, r := range rows {
+		if name != nil && !name.MatchString(*r.service.ServiceName) {
+			continue
+		}
+		if unhealthy && !isServiceUnhealthy(r.service) {
+			continue
+		}
+		if len(imageTag) > 0 {
+			tags, _ := getTags(r.td)
+			found := false
+			for _, tag := range tags {
+				if tag == imageTag {
+					found = true
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+		filtered = append(filtered, r)
+	}
 
-	flags := ecsDescribeCmd.Flags()
+	return filtered
+}
 
-	flags.StringVar(&ecsDescribeCmdCluster, "cluster", "", "ecs cluster")
+// isServiceUnhealthy checks to see if service is not running desired count
+func isServiceUnhealthy(s ecs.Service) bool {
+	return aws.Int64Value(s.RunningCount) != aws.Int64Value(s.DesiredCount)
+}
+
+// sortDescribeRows sorts by column, numerically if both values are numbers. rows are sorted by cluster and name by default
+func sortDescribeRows(rows []describeRow, sortBy string) {
+	descending := strings.HasPrefix(sortBy, "-")
+	column, ok := describeColumns[strings.TrimPrefix(sortBy, "-")]
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if !ok {
+			if rows[i].cluster != rows[j].cluster {
+				return rows[i].cluster < rows[j].cluster
+			}
+			return *rows[i].service.ServiceName < *rows[j].service.ServiceName
+		}
+
+		a, b := column.value(rows[i]), column.value(rows[j])
+		if descending {
+			a, b = b, a
+		}
 
+		x, errx := strconv.Atoi(a)
+		y, erry := strconv.Atoi(b)
+		if errx == nil && erry == nil {
+			return x < y
+		}
+		return a < b
+	})
 }
 
 func parseTaskDefinitionStr(taskdefARN string) string {
@@ -147,16 +405,78 @@ func toString(num *int64) string {
 	return v
 }
 
-func getTags(taskdef string) []string {
+func getTags(td *ecs.TaskDefinition) ([]string, []string) {
 	var tags []string
-	t, err := ecsw.DescribeTaskDefinition(taskdef)
-	if err == nil {
-		for _, cd := range t.TaskDefinition.ContainerDefinitions {
-			image := *cd.Image
-			colonIndex := strings.LastIndex(image, ":")
-			tag := image[colonIndex+1:]
-			tags = append(tags, tag)
+	var digests []string
+	if td != nil {
+		for _, cd := range td.ContainerDefinitions {
+			ref, err := imageref.Parse(*cd.Image)
+			if err != nil {
+				tags = append(tags, "?")
+				digests = append(digests, "")
+				continue
+			}
+			tags = append(tags, ref.TagOrDefault())
+			digests = append(digests, shortDigest(ref.Digest))
 		}
 	}
-	return tags
+	return tags, digests
+}
+
+// joinDigests joins digests in container order. empty if none of the images are pinned
+func joinDigests(digests []string) string {
+	joined := strings.Join(digests, ",")
+	if len(strings.Trim(joined, ",")) == 0 {
+		return ""
+	}
+	return joined
+}
+
+// formatDeployments formats status and counts of every deployment. e.g. PRIMARY 2/3, ACTIVE 1/0
+func formatDeployments(s ecs.Service) string {
+	deployments := []string{}
+	for _, d := range s.Deployments {
+		deployments = append(deployments, fmt.Sprintf("%s %d/%d", aws.StringValue(d.Status), aws.Int64Value(d.RunningCount), aws.Int64Value(d.DesiredCount)))
+	}
+	return strings.Join(deployments, ", ")
+}
+
+// formatLastEvent formats the most recent service event with its age
+func formatLastEvent(s ecs.Service) string {
+	if len(s.Events) == 0 {
+		return ""
+	}
+
+	e := s.Events[0]
+	message := aws.StringValue(e.Message)
+	// events repeat the service name in parentheses
+	message = strings.Replace(message, "(service "+aws.StringValue(s.ServiceName)+") ", "", 1)
+	if len(message) > 60 {
+		message = message[:57] + "..."
+	}
+
+	if e.CreatedAt == nil {
+		return message
+	}
+	return fmt.Sprintf("%s ago: %s", time.Since(*e.CreatedAt).Round(time.Second), message)
+}
+
+// formatSizeName returns name of the size the task definition reserves or cpu/memory if no size matches
+func formatSizeName(r describeRow) string {
+	if r.td == nil {
+		return ""
+	}
+
+	reserved := reservedResources(r.td)
+
+	sizes, _ := Sizes()
+	candidates := []rightsize.Size{}
+	for name, s := range sizes {
+		candidates = append(candidates, rightsize.Size{Name: name, CPU: s.CPU, Memory: s.Memory})
+	}
+
+	if name := rightsize.Match(candidates, reserved); len(name) > 0 {
+		return name
+	}
+	return fmt.Sprintf("%d/%d", reserved.CPU, reserved.Memory)
 }
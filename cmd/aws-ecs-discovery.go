@@ -0,0 +1,177 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/tools/awsapi/servicediscoveryw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go-v2/service/servicediscovery"
+	"github.com/spf13/pflag"
+)
+
+// discoveryFlags holds cloud map service discovery flags
+type discoveryFlags struct {
+	namespace string
+	name      string
+}
+
+func addDiscoveryFlags(flags *pflag.FlagSet, d *discoveryFlags) {
+
+	flags.StringVar(&d.namespace, "discovery-namespace", "", "optional: registers tasks in cloud map namespace. e.g. internal.local")
+
+	flags.StringVar(&d.name, "discovery-name", "", "optional: cloud map service name. defaults to service name")
+
+}
+
+// serviceRegistries creates or reuses cloud map service and returns the registry for ecs service
+func (d discoveryFlags) serviceRegistries(service, containerName string, containerPort int64, networkMode ecs.NetworkMode) []ecs.ServiceRegistry {
+	name := d.name
+	if len(name) == 0 {
+		name = service
+	}
+
+	namespaceID, err := servicediscoveryw.GetNamespaceIDByName(d.namespace)
+	ExitOnError(err, "finding cloud map namespace "+d.namespace)
+
+	existing, err := servicediscoveryw.FindService(namespaceID, name)
+	ExitOnError(err, "finding cloud map service "+name)
+
+	var registryArn string
+	if existing != nil {
+		Info("reusing cloud map service " + name + "." + d.namespace)
+		registryArn = *existing.Arn
+	} else {
+		// awsvpc tasks get their own ip. bridge tasks share host ip with dynamic ports so port must be published with srv records
+		recordType := servicediscovery.RecordTypeSrv
+		if networkMode == ecs.NetworkModeAwsvpc {
+			recordType = servicediscovery.RecordTypeA
+		}
+
+		result, err := servicediscoveryw.CreateService(namespaceID, name, recordType)
+		ExitOnError(err, "creating cloud map service "+name)
+		registryArn = *result.Service.Arn
+		Info("created cloud map service " + name + "." + d.namespace)
+	}
+
+	registry := ecs.ServiceRegistry{
+		RegistryArn: aws.String(registryArn),
+	}
+	if networkMode != ecs.NetworkModeAwsvpc {
+		registry.ContainerName = aws.String(containerName)
+		registry.ContainerPort = aws.Int64(containerPort)
+	}
+
+	return []ecs.ServiceRegistry{registry}
+}
+
+// PortContainerName returns name of the only container that maps container port. srv records point at that container
+func PortContainerName(td *ecs.TaskDefinition, port int64) (string, error) {
+	names := []string{}
+	for _, cd := range td.ContainerDefinitions {
+		for _, pm := range cd.PortMappings {
+			if aws.Int64Value(pm.ContainerPort) == port {
+				names = append(names, aws.StringValue(cd.Name))
+				break
+			}
+		}
+	}
+
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("no container of %s maps port %d", parseTaskDefinitionStr(aws.StringValue(td.TaskDefinitionArn)), port)
+	case 1:
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("more than one container maps port %d (%s). cloud map can only register one", port, strings.Join(names, ","))
+	}
+}
+
+// DeleteServiceRegistries deletes cloud map services ecs service was registered with. services morgan did not create
+// and services other ecs services are still registered with are left alone.
+// ecs deregisters instances as tasks stop, so deletion is retried while instances remain
+func DeleteServiceRegistries(service ecs.Service, timeout int64) {
+	for _, r := range service.ServiceRegistries {
+		arn := aws.StringValue(r.RegistryArn)
+		id := arn[strings.LastIndex(arn, "/")+1:]
+
+		if reason := keepServiceRegistry(service, id, arn); len(reason) > 0 {
+			Info("keeping cloud map service " + id + ". " + reason)
+			continue
+		}
+
+		deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+		for {
+			_, err := servicediscoveryw.DeleteService(id)
+			if err == nil {
+				Info("deleted cloud map service " + id)
+				break
+			}
+
+			if !strings.Contains(err.Error(), "ResourceInUse") || time.Now().After(deadline) {
+				Failure("deleting cloud map service " + id + ". delete it manually once its instances are deregistered")
+				Debug(err.Error())
+				break
+			}
+
+			time.Sleep(5 * time.Second)
+		}
+	}
+}
+
+// keepServiceRegistry returns reason cloud map service must not be deleted. empty reason means it can be deleted
+func keepServiceRegistry(service ecs.Service, id, arn string) string {
+	registry, err := servicediscoveryw.GetService(id)
+	if err != nil {
+		Debug(err.Error())
+		return "it could not be described"
+	}
+	if aws.StringValue(registry.Description) != servicediscoveryw.CreatedByMorgan {
+		return "it was not created by morgan"
+	}
+
+	services, err := describeAllServices()
+	if err != nil {
+		Debug(err.Error())
+		return "ecs services registered with it could not be checked"
+	}
+
+	users := []string{}
+	for _, s := range services {
+		if aws.StringValue(s.ServiceArn) == aws.StringValue(service.ServiceArn) || aws.StringValue(s.Status) == "INACTIVE" {
+			continue
+		}
+		for _, other := range s.ServiceRegistries {
+			if aws.StringValue(other.RegistryArn) == arn {
+				users = append(users, aws.StringValue(s.ServiceName))
+			}
+		}
+	}
+	if len(users) > 0 {
+		return "it is still used by " + strings.Join(users, ",")
+	}
+
+	return ""
+}
@@ -0,0 +1,77 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsEnvListCmdShowSecrets bool
+
+var ecsEnvListCmd = &cobra.Command{
+	Use:     "list <service name>",
+	Short:   "Lists environment variables",
+	Long:    `Lists environment variables of the container in the current task definition of the service`,
+	Example: "foo-svc --cluster api-cluster",
+	Aliases: []string{"ls"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+		cluster := ResolveCluster(ecsEnvCmdCluster, service)
+
+		_, td := DescribeServiceAndTaskDefinition(cluster, service)
+
+		i, err := FindContainerDefinition(td, ecsEnvCmdContainer, service)
+		ExitOnError(err, "finding container")
+
+		envs := td.ContainerDefinitions[i].Environment
+		sortEnvironment(envs)
+
+		Newline()
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Value"})
+
+		for _, env := range envs {
+			value := *env.Value
+			if !ecsEnvListCmdShowSecrets {
+				value = MaskEnvValue(*env.Name, value)
+			}
+			table.Append([]string{*env.Name, value})
+		}
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecsEnvCmd.AddCommand(ecsEnvListCmd)
+
+	flags := ecsEnvListCmd.Flags()
+
+	flags.BoolVar(&ecsEnvListCmdShowSecrets, "show-secrets", false, "optional: shows values that look like secrets")
+
+}
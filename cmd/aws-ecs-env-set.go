@@ -0,0 +1,88 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsEnvSetCmdNoDeploy bool
+var ecsEnvSetCmdTimeout int64
+var ecsEnvSetCmdWaitForServiceStable bool
+
+var ecsEnvSetCmd = &cobra.Command{
+	Use:     "set <service name> <key=value...>",
+	Short:   "Sets environment variables",
+	Long:    `Sets environment variables and deploys a new task definition revision`,
+	Example: "foo-svc LOG_LEVEL=debug FEATURE_X=on --cluster api-cluster",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+		cluster := ResolveCluster(ecsEnvCmdCluster, service)
+		vars := ConvertKeyValuePairArgSliceToMap(args[1:])
+
+		s, td := DescribeServiceAndTaskDefinition(cluster, service)
+
+		i, err := FindContainerDefinition(td, ecsEnvCmdContainer, service)
+		ExitOnError(err, "finding container")
+
+		cd := &td.ContainerDefinitions[i]
+
+		// replace existing values first then append the new ones
+		for j, env := range cd.Environment {
+			if v, ok := vars[*env.Name]; ok {
+				cd.Environment[j].Value = aws.String(v)
+				Info(*env.Name + "=" + MaskEnvValue(*env.Name, v))
+				delete(vars, *env.Name)
+			}
+		}
+		for k, v := range vars {
+			cd.Environment = append(cd.Environment, ecs.KeyValuePair{
+				Name:  aws.String(k),
+				Value: aws.String(v),
+			})
+			Info(k + "=" + MaskEnvValue(k, v))
+		}
+		sortEnvironment(cd.Environment)
+
+		registerAndDeploy("env-set", cluster, s, td, ecsEnvSetCmdNoDeploy, ecsEnvSetCmdWaitForServiceStable, ecsEnvSetCmdTimeout)
+
+		Success("setting environment variables")
+
+	},
+}
+
+func init() {
+
+	ecsEnvCmd.AddCommand(ecsEnvSetCmd)
+
+	flags := ecsEnvSetCmd.Flags()
+
+	flags.BoolVar(&ecsEnvSetCmdNoDeploy, "no-deploy", false, "optional: only registers new task definition revision")
+
+	flags.Int64Var(&ecsEnvSetCmdTimeout, "timeout", 300, "optional: service stable timeout")
+
+	flags.BoolVarP(&ecsEnvSetCmdWaitForServiceStable, "service-stable", "w", false, "optional: waits for service to become stable")
+
+}
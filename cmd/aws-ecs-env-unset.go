@@ -0,0 +1,93 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsEnvUnsetCmdNoDeploy bool
+var ecsEnvUnsetCmdTimeout int64
+var ecsEnvUnsetCmdWaitForServiceStable bool
+
+var ecsEnvUnsetCmd = &cobra.Command{
+	Use:     "unset <service name> <keys...>",
+	Short:   "Unsets environment variables",
+	Long:    `Removes environment variables and deploys a new task definition revision`,
+	Example: "foo-svc LOG_LEVEL FEATURE_X --cluster api-cluster",
+	Args:    cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+		cluster := ResolveCluster(ecsEnvCmdCluster, service)
+
+		keys := map[string]bool{}
+		for _, key := range args[1:] {
+			// tolerate KEY=VALUE form
+			key = strings.SplitN(strings.TrimSpace(key), "=", 2)[0]
+			keys[key] = true
+		}
+
+		s, td := DescribeServiceAndTaskDefinition(cluster, service)
+
+		i, err := FindContainerDefinition(td, ecsEnvCmdContainer, service)
+		ExitOnError(err, "finding container")
+
+		cd := &td.ContainerDefinitions[i]
+
+		envs := []ecs.KeyValuePair{}
+		for _, env := range cd.Environment {
+			if keys[*env.Name] {
+				Info("removing " + *env.Name)
+				continue
+			}
+			envs = append(envs, env)
+		}
+
+		if len(envs) == len(cd.Environment) {
+			Failure("none of the specified environment variables are set")
+			return
+		}
+		cd.Environment = envs
+
+		registerAndDeploy("env-unset", cluster, s, td, ecsEnvUnsetCmdNoDeploy, ecsEnvUnsetCmdWaitForServiceStable, ecsEnvUnsetCmdTimeout)
+
+		Success("unsetting environment variables")
+
+	},
+}
+
+func init() {
+
+	ecsEnvCmd.AddCommand(ecsEnvUnsetCmd)
+
+	flags := ecsEnvUnsetCmd.Flags()
+
+	flags.BoolVar(&ecsEnvUnsetCmdNoDeploy, "no-deploy", false, "optional: only registers new task definition revision")
+
+	flags.Int64Var(&ecsEnvUnsetCmdTimeout, "timeout", 300, "optional: service stable timeout")
+
+	flags.BoolVarP(&ecsEnvUnsetCmdWaitForServiceStable, "service-stable", "w", false, "optional: waits for service to become stable")
+
+}
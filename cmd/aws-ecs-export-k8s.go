@@ -0,0 +1,337 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/k8s"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsExportK8sCmdCluster string
+var ecsExportK8sCmdNamespace string
+var ecsExportK8sCmdOutput string
+
+var ecsExportK8sCmd = &cobra.Command{
+	Use:   "export-k8s <service names>",
+	Short: "Exports ecs as kubernetes manifests",
+	Long: `Exports ecs services and their task definitions as kubernetes Deployment, Service and ConfigMap.
+
+* replicas           : desired count
+* rolling update     : minimum healthy and maximum percent
+* resources          : cpu units and memory reservation as requests, memory as limit
+* probes             : health check as liveness and readiness exec probes
+* environment        : ConfigMap <service>-env. values that look like secrets and ecs secrets
+                       are referenced from Secret <service>-secrets which has to be created separately
+
+Fields that have no kubernetes equivalent are reported on stderr.`,
+	Example: "foo-svc bar-svc --namespace apps -o manifests.yaml",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		targets, err := ResolveServiceTargets(ecsExportK8sCmdCluster, args)
+		ExitOnError(err, "resolving clusters for services")
+
+		objects := []interface{}{}
+		for _, t := range targets {
+			s, err := describeService(t)
+			ExitOnError(err, "describing service "+t.Service)
+
+			result, err := ecsw.DescribeTaskDefinition(*s.TaskDefinition)
+			ExitOnError(err, "describing task definition of "+t.Service)
+
+			o, notes := ExportK8s(s, result.TaskDefinition, ecsExportK8sCmdNamespace)
+			objects = append(objects, o...)
+
+			for _, note := range notes {
+				fmt.Fprintln(os.Stderr, "# "+t.Service+": "+note)
+			}
+		}
+
+		data, err := k8s.Marshal(objects...)
+		ExitOnError(err, "marshalling manifests")
+
+		if len(ecsExportK8sCmdOutput) == 0 {
+			fmt.Print(string(data))
+			return
+		}
+
+		err = ioutil.WriteFile(ecsExportK8sCmdOutput, data, 0644)
+		ExitOnError(err, "writing "+ecsExportK8sCmdOutput)
+
+		Success("writing " + ecsExportK8sCmdOutput)
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsExportK8sCmd)
+
+	flags := ecsExportK8sCmd.Flags()
+
+	flags.StringVarP(&ecsExportK8sCmdCluster, "cluster", "c", "", "optional: ecs cluster")
+
+	flags.StringVarP(&ecsExportK8sCmdNamespace, "namespace", "n", "", "optional: kubernetes namespace")
+
+	flags.StringVarP(&ecsExportK8sCmdOutput, "output", "o", "", "optional: file to write to. defaults to stdout")
+
+}
+
+// ExportK8s translates service and its task definition into kubernetes objects. notes describe what has no equivalent
+func ExportK8s(s ecs.Service, td *ecs.TaskDefinition, namespace string) ([]interface{}, []string) {
+	name := aws.StringValue(s.ServiceName)
+	notes := []string{}
+
+	d := k8s.NewDeployment(name, namespace, aws.Int64Value(s.DesiredCount))
+	if dc := s.DeploymentConfiguration; dc != nil && dc.MinimumHealthyPercent != nil && dc.MaximumPercent != nil {
+		d.Spec.Strategy = &k8s.DeploymentStrategy{
+			Type: "RollingUpdate",
+			RollingUpdate: &k8s.RollingUpdate{
+				MaxUnavailable: fmt.Sprintf("%d%%", 100-*dc.MinimumHealthyPercent),
+				MaxSurge:       fmt.Sprintf("%d%%", *dc.MaximumPercent-100),
+			},
+		}
+	}
+
+	svc := k8s.NewService(name, namespace)
+	configMaps := []interface{}{}
+	secretName := name + "-secrets"
+
+	volumes := map[string]ecs.Volume{}
+	for _, v := range td.Volumes {
+		volumes[aws.StringValue(v.Name)] = v
+	}
+	podVolumes := map[string]k8s.Volume{}
+
+	for _, cd := range td.ContainerDefinitions {
+		cname := aws.StringValue(cd.Name)
+		c := k8s.Container{
+			Name:       cname,
+			Image:      aws.StringValue(cd.Image),
+			Command:    cd.EntryPoint,
+			Args:       cd.Command,
+			WorkingDir: aws.StringValue(cd.WorkingDirectory),
+		}
+
+		for _, pm := range cd.PortMappings {
+			port := aws.Int64Value(pm.ContainerPort)
+			protocol := strings.ToUpper(string(pm.Protocol))
+			c.Ports = append(c.Ports, k8s.ContainerPort{ContainerPort: port, Protocol: protocol})
+			svc.Spec.Ports = append(svc.Spec.Ports, k8s.ServicePort{
+				Name:       fmt.Sprintf("%s-%d", cname, port),
+				Port:       port,
+				TargetPort: port,
+				Protocol:   protocol,
+			})
+			if aws.Int64Value(pm.HostPort) > 0 {
+				notes = append(notes, fmt.Sprintf("%s: host port %d is not exported. use the Service instead", cname, *pm.HostPort))
+			}
+		}
+
+		// every container gets its own config map when there is more than one container
+		configMapName := name + "-env"
+		if len(td.ContainerDefinitions) > 1 {
+			configMapName = name + "-" + cname + "-env"
+		}
+		env := map[string]string{}
+		for _, kv := range cd.Environment {
+			key, value := aws.StringValue(kv.Name), aws.StringValue(kv.Value)
+			if LooksLikeSecret(key, value) {
+				c.Env = append(c.Env, secretEnv(key, secretName))
+				notes = append(notes, fmt.Sprintf("%s: %s looks like a secret and is referenced from secret %s", cname, key, secretName))
+				continue
+			}
+			env[key] = value
+		}
+		if len(env) > 0 {
+			c.EnvFrom = []k8s.EnvFromSource{{ConfigMapRef: &k8s.LocalObjectReference{Name: configMapName}}}
+			configMaps = append(configMaps, k8s.NewConfigMap(configMapName, namespace, env))
+		}
+
+		for _, secret := range cd.Secrets {
+			key := aws.StringValue(secret.Name)
+			c.Env = append(c.Env, secretEnv(key, secretName))
+			notes = append(notes, fmt.Sprintf("%s: populate %s of secret %s from %s", cname, key, secretName, aws.StringValue(secret.ValueFrom)))
+		}
+
+		c.Resources = containerResources(cd)
+
+		if hc := cd.HealthCheck; hc != nil && len(hc.Command) > 0 {
+			probe := &k8s.Probe{
+				Exec:                &k8s.ExecAction{Command: probeCommand(hc.Command)},
+				InitialDelaySeconds: aws.Int64Value(hc.StartPeriod),
+				PeriodSeconds:       aws.Int64Value(hc.Interval),
+				TimeoutSeconds:      aws.Int64Value(hc.Timeout),
+				FailureThreshold:    aws.Int64Value(hc.Retries),
+			}
+			c.LivenessProbe = probe
+			c.ReadinessProbe = probe
+		}
+
+		if aws.BoolValue(cd.Privileged) {
+			c.SecurityContext = &k8s.SecurityContext{Privileged: true}
+		}
+
+		for _, mp := range cd.MountPoints {
+			source := aws.StringValue(mp.SourceVolume)
+			c.VolumeMounts = append(c.VolumeMounts, k8s.VolumeMount{Name: source, MountPath: aws.StringValue(mp.ContainerPath), ReadOnly: aws.BoolValue(mp.ReadOnly)})
+
+			if _, ok := podVolumes[source]; ok {
+				continue
+			}
+			v, note := podVolume(volumes[source])
+			podVolumes[source] = v
+			if len(note) > 0 {
+				notes = append(notes, note)
+			}
+		}
+
+		if cd.LinuxParameters != nil {
+			for i, t := range cd.LinuxParameters.Tmpfs {
+				vname := fmt.Sprintf("%s-tmpfs-%d", cname, i)
+				podVolumes[vname] = k8s.Volume{Name: vname, EmptyDir: &k8s.EmptyDirVolume{Medium: "Memory", SizeLimit: k8s.Memory(aws.Int64Value(t.Size))}}
+				c.VolumeMounts = append(c.VolumeMounts, k8s.VolumeMount{Name: vname, MountPath: aws.StringValue(t.ContainerPath)})
+			}
+		}
+
+		notes = append(notes, unexportedContainerFields(cd)...)
+
+		d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, c)
+	}
+
+	vnames := []string{}
+	for vname := range podVolumes {
+		vnames = append(vnames, vname)
+	}
+	sort.Strings(vnames)
+	for _, vname := range vnames {
+		d.Spec.Template.Spec.Volumes = append(d.Spec.Template.Spec.Volumes, podVolumes[vname])
+	}
+
+	if len(s.PlacementConstraints) > 0 || len(s.PlacementStrategy) > 0 {
+		notes = append(notes, "placement constraints and strategies are not exported. use node affinity and topology spread constraints")
+	}
+	if len(s.ServiceRegistries) > 0 {
+		notes = append(notes, "cloud map registration is not exported. the Service gets cluster dns name "+name)
+	}
+	if len(s.LoadBalancers) > 0 {
+		notes = append(notes, "load balancers are not exported. use Ingress or Service of type LoadBalancer")
+	}
+	if td.NetworkMode == ecs.NetworkModeHost {
+		notes = append(notes, "host network mode is not exported. set hostNetwork on the pod if it is really needed")
+	}
+
+	objects := append(configMaps, d)
+	if len(svc.Spec.Ports) > 0 {
+		objects = append(objects, svc)
+	}
+
+	return objects, notes
+}
+
+func secretEnv(key, secretName string) k8s.EnvVar {
+	return k8s.EnvVar{Name: key, ValueFrom: &k8s.EnvVarSource{SecretKeyRef: &k8s.KeySelector{Name: secretName, Key: key}}}
+}
+
+// containerResources requests cpu and memory reservation and limits memory
+func containerResources(cd ecs.ContainerDefinition) *k8s.Resources {
+	r := &k8s.Resources{Requests: map[string]string{}, Limits: map[string]string{}}
+
+	if aws.Int64Value(cd.Cpu) > 0 {
+		r.Requests["cpu"] = k8s.CPU(*cd.Cpu)
+	}
+	if cd.MemoryReservation != nil {
+		r.Requests["memory"] = k8s.Memory(*cd.MemoryReservation)
+	}
+	if cd.Memory != nil {
+		r.Limits["memory"] = k8s.Memory(*cd.Memory)
+		if cd.MemoryReservation == nil {
+			r.Requests["memory"] = k8s.Memory(*cd.Memory)
+		}
+	}
+
+	if len(r.Requests) == 0 && len(r.Limits) == 0 {
+		return nil
+	}
+	return r
+}
+
+// probeCommand converts docker health check command. CMD-SHELL runs with shell as docker does
+func probeCommand(command []string) []string {
+	switch command[0] {
+	case "CMD-SHELL":
+		return []string{"sh", "-c", strings.Join(command[1:], " ")}
+	case "CMD":
+		return command[1:]
+	default:
+		return command
+	}
+}
+
+func podVolume(v ecs.Volume) (k8s.Volume, string) {
+	name := aws.StringValue(v.Name)
+
+	switch {
+	case v.Host != nil && v.Host.SourcePath != nil:
+		return k8s.Volume{Name: name, HostPath: &k8s.HostPathVolume{Path: *v.Host.SourcePath}}, ""
+	case v.DockerVolumeConfiguration != nil && v.DockerVolumeConfiguration.Scope == ecs.ScopeShared:
+		return k8s.Volume{Name: name, EmptyDir: &k8s.EmptyDirVolume{}},
+			fmt.Sprintf("docker volume %s survives tasks but emptyDir does not. use a PersistentVolumeClaim if data must be kept", name)
+	default:
+		return k8s.Volume{Name: name, EmptyDir: &k8s.EmptyDirVolume{}}, ""
+	}
+}
+
+// unexportedContainerFields reports container fields that have no kubernetes equivalent
+func unexportedContainerFields(cd ecs.ContainerDefinition) []string {
+	name := aws.StringValue(cd.Name)
+	notes := []string{}
+
+	fields := []struct {
+		set   bool
+		field string
+	}{
+		{len(cd.Links) > 0, "links. containers of a pod share localhost"},
+		{cd.LogConfiguration != nil, "log configuration. logs are collected from stdout by the cluster"},
+		{len(cd.Ulimits) > 0, "ulimits"},
+		{len(cd.DockerLabels) > 0, "docker labels"},
+		{len(cd.VolumesFrom) > 0, "volumes from"},
+		{len(cd.DependsOn) > 0, "container dependencies. consider init containers"},
+		{cd.Hostname != nil, "hostname"},
+	}
+
+	for _, f := range fields {
+		if f.set {
+			notes = append(notes, name+": "+f.field+" not exported")
+		}
+	}
+
+	return notes
+}
@@ -0,0 +1,364 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/hooks"
+	"github.com/7onetella/morgan/internal/notify"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/7onetella/morgan/tools/history"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ecsHistoryCmdCluster string
+var ecsHistoryCmdLimit int
+
+var ecsHistoryCmd = &cobra.Command{
+	Use:   "history <service name>",
+	Short: "Shows deployment history",
+	Long: `Shows who deployed what and when. Every create, update, rollback, env set, env unset, start, stop and delete is recorded.
+
+History is kept in ~/.morgan/history.jsonl by default. A shared ledger can be kept in consul kv instead:
+
+history:
+  backend: consul
+  consul:
+    address: consul.example.com:8500
+    prefix: morgan/history
+    token: <acl token>`,
+	Example: "foo-svc --limit 10",
+	Aliases: []string{"deployments"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+
+		ledger, err := HistoryLedger()
+		ExitOnError(err, "initializing history ledger")
+
+		// filter by cluster before limiting
+		limit := ecsHistoryCmdLimit
+		if len(ecsHistoryCmdCluster) > 0 {
+			limit = 0
+		}
+
+		records, err := ledger.List(service, limit)
+		ExitOnError(err, "reading history")
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Time", "User", "Action", "Cluster", "Task Definition", "Desired", "Images", "Outcome"})
+		shown := 0
+		for _, r := range records {
+			if len(ecsHistoryCmdCluster) > 0 && r.Cluster != ecsHistoryCmdCluster {
+				continue
+			}
+			if ecsHistoryCmdLimit > 0 && shown == ecsHistoryCmdLimit {
+				break
+			}
+			table.Append([]string{
+				r.Time.Local().Format("2006-01-02 15:04:05"),
+				r.User,
+				r.Action,
+				r.Cluster,
+				formatChange(r.OldTaskDefinition, r.NewTaskDefinition),
+				formatChange(fmt.Sprintf("%d", r.OldDesiredCount), fmt.Sprintf("%d", r.NewDesiredCount)),
+				strings.Join(r.Images, "\n"),
+				formatOutcome(r),
+			})
+			shown++
+		}
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsHistoryCmd)
+
+	flags := ecsHistoryCmd.Flags()
+
+	flags.StringVarP(&ecsHistoryCmdCluster, "cluster", "c", "", "optional: only show records of cluster")
+
+	flags.IntVarP(&ecsHistoryCmdLimit, "limit", "n", 20, "optional: number of records to show. 0 shows all")
+
+}
+
+// HistoryLedger returns ledger configured under history in ~/.morgan.yaml
+func HistoryLedger() (history.Ledger, error) {
+	backend := viper.GetString("history.backend")
+
+	switch backend {
+	case "", "file":
+		path := viper.GetString("history.path")
+		if len(path) == 0 {
+			home, err := homedir.Dir()
+			if err != nil {
+				return nil, err
+			}
+			path = filepath.Join(home, ".morgan", "history.jsonl")
+		}
+		path, err := homedir.Expand(path)
+		if err != nil {
+			return nil, err
+		}
+		return history.NewFileLedger(path), nil
+	case "consul":
+		address := viper.GetString("history.consul.address")
+		if len(address) == 0 {
+			address = "127.0.0.1:8500"
+		}
+		prefix := viper.GetString("history.consul.prefix")
+		if len(prefix) == 0 {
+			prefix = "morgan/history"
+		}
+		return history.NewConsulLedger(address, prefix, viper.GetString("history.consul.token")), nil
+	default:
+		return nil, errors.New("unknown history backend " + backend + ". must be file or consul")
+	}
+}
+
+// RecordHistory appends record to the ledger. failing to record does not fail the action that was already carried out
+func RecordHistory(r history.Record) {
+	ledger, err := HistoryLedger()
+	if err == nil {
+		err = ledger.Append(r)
+	}
+	if err != nil {
+		Failure("recording deployment history")
+		Debug(err.Error())
+	}
+}
+
+// deployment tracks an action so that it is recorded in history whether it succeeds or not
+type deployment struct {
+	history.Record
+	finished bool
+	// changed is set once the service is actually changed. post hooks only run for changed services
+	changed bool
+	// taskDefinitionArn is the full arn of the new task definition passed to hooks
+	taskDefinitionArn string
+	// oldImages is looked up once for hooks and notifications
+	oldImages []string
+}
+
+// BeginDeployment starts tracking action on service. if morgan exits on error before Finish is called, failure is recorded
+func BeginDeployment(action, cluster, service string) *deployment {
+	d := &deployment{
+		Record: history.Record{
+			Time:    time.Now(),
+			User:    currentUser(),
+			Action:  action,
+			Cluster: cluster,
+			Service: service,
+		},
+	}
+
+	exitHooks = append(exitHooks, d.Finish)
+
+	return d
+}
+
+// Before records state of service before the action
+func (d *deployment) Before(s ecs.Service) {
+	if s.TaskDefinition != nil {
+		d.OldTaskDefinition = parseTaskDefinitionStr(*s.TaskDefinition)
+	}
+	if s.DesiredCount != nil {
+		d.OldDesiredCount = *s.DesiredCount
+	}
+}
+
+// After records task definition and desired count the action resulted in
+func (d *deployment) After(taskdef string, desiredCount int64) {
+	d.taskDefinitionArn = taskdef
+	d.NewTaskDefinition = parseTaskDefinitionStr(taskdef)
+	d.NewDesiredCount = desiredCount
+}
+
+// Pre runs pre hooks. it must be called after the task definition is registered and right before the service is changed
+// so that hooks see the new task definition arn and a failing hook aborts the change
+func (d *deployment) Pre() error {
+	return d.runHooks(hooks.PhasePre)
+}
+
+// Changed marks the service as changed by the action. it must be called right after the change is made
+func (d *deployment) Changed() {
+	d.changed = true
+}
+
+// Finish records outcome of the action
+func (d *deployment) Finish(err error) {
+	if d.finished {
+		return
+	}
+	d.finished = true
+
+	d.Outcome = history.OutcomeSucceeded
+	if err != nil {
+		d.Outcome = history.OutcomeFailed
+		d.Error = err.Error()
+	}
+
+	RecordHistory(d.Record)
+
+	// nothing to follow up on when pre hooks, checks or registration failed before the service was changed
+	if d.changed {
+		if err := d.runHooks(hooks.PhasePost); err != nil {
+			Failure("running post hooks")
+			Debug(err.Error())
+		}
+	}
+
+	d.notify()
+}
+
+// notify posts outcome of deployment to webhooks
+func (d *deployment) notify() {
+	if !NotificationsEnabled(d.Action) {
+		return
+	}
+
+	Notify(notify.Event{
+		Kind:      "ecs",
+		Action:    d.Action,
+		Target:    d.Service,
+		Cluster:   d.Cluster,
+		User:      d.User,
+		OldImages: d.previousImages(),
+		NewImages: d.Images,
+		Duration:  time.Since(d.Time),
+		Succeeded: d.Outcome == history.OutcomeSucceeded,
+		Error:     d.Error,
+	})
+}
+
+// runHooks runs hooks of phase that apply to the action. the context is only built when there are hooks to run
+func (d *deployment) runHooks(phase string) error {
+	list, err := HooksFor(phase, d.Action, d.Service)
+	if err != nil || len(list) == 0 {
+		return err
+	}
+
+	return RunHooks(phase, list, d.hookContext(phase))
+}
+
+// hookContext describes the deployment to hooks
+func (d *deployment) hookContext(phase string) hooks.Context {
+	return hooks.Context{
+		Phase:             phase,
+		Action:            d.Action,
+		Service:           d.Service,
+		Cluster:           d.Cluster,
+		OldTaskDefinition: d.OldTaskDefinition,
+		NewTaskDefinition: d.taskDefinitionArn,
+		OldImages:         d.previousImages(),
+		NewImages:         d.Images,
+		Outcome:           d.Outcome,
+		Error:             d.Error,
+	}
+}
+
+// previousImages returns images of the old task definition
+func (d *deployment) previousImages() []string {
+	if d.oldImages != nil || len(d.OldTaskDefinition) == 0 {
+		return d.oldImages
+	}
+
+	if result, err := ecsw.DescribeTaskDefinition(d.OldTaskDefinition); err == nil {
+		d.oldImages = taskDefinitionImages(result.TaskDefinition)
+	}
+
+	return d.oldImages
+}
+
+// RecordServicesDesiredCount records outcome of start and stop for every service
+func RecordServicesDesiredCount(items []*serviceProgress, action string) {
+	user := currentUser()
+
+	for _, item := range items {
+		r := history.Record{
+			Time:              item.Started,
+			User:              user,
+			Action:            action,
+			Cluster:           item.Cluster,
+			Service:           item.Service,
+			OldTaskDefinition: parseTaskDefinitionStr(item.TaskDefinition),
+			NewTaskDefinition: parseTaskDefinitionStr(item.TaskDefinition),
+			OldDesiredCount:   item.OldDesiredCount,
+			NewDesiredCount:   item.DesiredCount,
+			Outcome:           history.OutcomeSucceeded,
+		}
+		// service could not be described so it was never updated
+		if r.Time.IsZero() {
+			r.Time = time.Now()
+		}
+		if item.State != stateSucceeded {
+			r.Outcome = history.OutcomeFailed
+			r.Error = strings.TrimSpace(item.State + " " + item.Detail)
+		}
+		RecordHistory(r)
+	}
+}
+
+// taskDefinitionImages returns images of all containers
+func taskDefinitionImages(td *ecs.TaskDefinition) []string {
+	images := []string{}
+	for _, cd := range td.ContainerDefinitions {
+		if cd.Image != nil {
+			images = append(images, *cd.Image)
+		}
+	}
+	return images
+}
+
+func currentUser() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+func formatChange(before, after string) string {
+	if before == after || len(before) == 0 {
+		return after
+	}
+	return before + " -> " + after
+}
+
+func formatOutcome(r history.Record) string {
+	if r.Outcome == history.OutcomeSucceeded {
+		return green(r.Outcome)
+	}
+	return red(strings.TrimSpace(r.Outcome + " " + r.Error))
+}
@@ -0,0 +1,172 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/hooks"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/viper"
+)
+
+// HookConfig reads hooks from ~/.morgan.yaml. global hooks run for every service, followed by hooks of the service.
+// services are listed with name since config keys are not case sensitive.
+//
+// e.g.
+//
+//	hooks:
+//	  pre:
+//	    - command: ./smoke-test.sh
+//	      actions: [create, update]
+//	  post:
+//	    - url: https://chat.example.com/hooks/deployments
+//	  services:
+//	    - name: foo-svc
+//	      post:
+//	        - task: foo-svc-warm-cache
+//	          timeout: 300
+func HookConfig() (hooks.Config, error) {
+	config := hooks.Config{}
+	err := viper.UnmarshalKey("hooks", &config)
+
+	return config, err
+}
+
+// HooksFor returns hooks of phase that apply to action on service
+func HooksFor(phase, action, service string) ([]hooks.Hook, error) {
+	config, err := HookConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return config.For(phase, action, service), nil
+}
+
+// RunHooks runs hooks of phase. pre hooks stop at the first failure and return it.
+// failing post hooks are reported and the rest still run
+func RunHooks(phase string, list []hooks.Hook, c hooks.Context) error {
+	runner := hooks.Runner{RunTask: runTaskHook}
+
+	for _, h := range list {
+		Info(fmt.Sprintf("running %s hook %s", phase, h))
+
+		output, err := runner.Run(h, c)
+		Debug(output)
+		if err == nil {
+			continue
+		}
+
+		err = fmt.Errorf("%s hook %s failed: %v", phase, h, err)
+		if phase == hooks.PhasePre {
+			return err
+		}
+		Failure(err.Error())
+	}
+
+	return nil
+}
+
+// runTaskHook runs one-off task with the deployment context in environment of every container and waits for it to stop
+func runTaskHook(h hooks.Hook, c hooks.Context) (string, error) {
+	cluster := h.Cluster
+	if len(cluster) == 0 {
+		cluster = c.Cluster
+	}
+
+	result, err := ecsw.DescribeTaskDefinition(h.Task)
+	if err != nil {
+		return "", err
+	}
+
+	envs := []ecs.KeyValuePair{}
+	for _, kv := range c.Env() {
+		tokens := strings.SplitN(kv, "=", 2)
+		envs = append(envs, ecs.KeyValuePair{Name: aws.String(tokens[0]), Value: aws.String(tokens[1])})
+	}
+
+	overrides := []ecs.ContainerOverride{}
+	for _, cd := range result.TaskDefinition.ContainerDefinitions {
+		overrides = append(overrides, ecs.ContainerOverride{Name: cd.Name, Environment: envs})
+	}
+
+	result2, err := ecsw.RunTask(cluster, *result.TaskDefinition.TaskDefinitionArn, "morgan-hook", overrides)
+	if err != nil {
+		return "", err
+	}
+	if len(result2.Tasks) == 0 {
+		reasons := []string{}
+		for _, f := range result2.Failures {
+			reasons = append(reasons, aws.StringValue(f.Reason))
+		}
+		return "", errors.New("task was not started: " + strings.Join(reasons, ","))
+	}
+	arn := *result2.Tasks[0].TaskArn
+
+	deadline := time.Now().Add(h.TimeoutDuration())
+	for {
+		result3, err := ecsw.DescribeTasks(cluster, arn)
+		if err != nil {
+			return "", err
+		}
+
+		if len(result3.Tasks) > 0 && aws.StringValue(result3.Tasks[0].LastStatus) == "STOPPED" {
+			return taskExitOutput(result3.Tasks[0])
+		}
+
+		if time.Now().After(deadline) {
+			ecsw.StopTask(cluster, arn, "hook timed out")
+			return "", fmt.Errorf("task %s timed out after %s", taskID(arn), h.TimeoutDuration())
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// taskExitOutput summarizes exit codes of stopped task. any container exiting with non-zero code fails the hook
+func taskExitOutput(task ecs.Task) (string, error) {
+	lines := []string{}
+	failed := false
+
+	for _, c := range task.Containers {
+		if c.ExitCode == nil {
+			failed = true
+			lines = append(lines, fmt.Sprintf("%s stopped without exit code: %s", aws.StringValue(c.Name), aws.StringValue(c.Reason)))
+			continue
+		}
+		if *c.ExitCode != 0 {
+			failed = true
+		}
+		lines = append(lines, fmt.Sprintf("%s exited with %d", aws.StringValue(c.Name), *c.ExitCode))
+	}
+
+	output := strings.Join(lines, "\n")
+	if failed {
+		return output, fmt.Errorf("task %s failed. %s", taskID(*task.TaskArn), aws.StringValue(task.StoppedReason))
+	}
+
+	return output, nil
+}
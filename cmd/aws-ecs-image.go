@@ -0,0 +1,97 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/7onetella/morgan/internal/imageref"
+	"github.com/7onetella/morgan/tools/awsapi/ecrw"
+	"github.com/7onetella/morgan/tools/registry"
+)
+
+// ParseImage parses docker image reference and exits if it is invalid
+func ParseImage(image string) imageref.Reference {
+	ref, err := imageref.Parse(image)
+	ExitOnError(err, "parsing image "+image)
+
+	return ref
+}
+
+// NewRegistryClient returns registry client for the registry the image is hosted in
+func NewRegistryClient(ref imageref.Reference, insecure bool) *registry.Client {
+	client := registry.New()
+	client.Insecure = insecure
+
+	// ecr requires basic auth with token obtained from ecr api
+	if ref.IsECR() {
+		registryID, region := ref.ECRRegistryID()
+		username, password, err := ecrw.GetRegistryCredentials(registryID, region)
+		ExitOnError(err, "getting ecr authorization token")
+		client.Username = username
+		client.Password = password
+	}
+
+	return client
+}
+
+// PinImageDigest resolves image tag to immutable digest. e.g. app:1.0.0 becomes app:1.0.0@sha256:...
+func PinImageDigest(image string, insecure bool) string {
+	ref := ParseImage(image)
+	if len(ref.Digest) > 0 {
+		return image
+	}
+
+	digest, err := NewRegistryClient(ref, insecure).ResolveDigest(ref)
+	ExitOnError(err, "resolving digest of "+image)
+
+	pinned := ref.WithDigest(digest).String()
+	Info("pinned " + pinned)
+
+	return pinned
+}
+
+// VerifyImage fails when ecr image does not exist. images hosted elsewhere are not checked
+func VerifyImage(image string) error {
+	ref, err := imageref.Parse(image)
+	if err != nil || !ref.IsECR() {
+		return err
+	}
+
+	registryID, region := ref.ECRRegistryID()
+	exists, err := ecrw.ImageExists(registryID, region, ref.Path, ref.TagOrDefault(), ref.Digest)
+	if err != nil {
+		return fmt.Errorf("checking image %s: %v", image, err)
+	}
+	if !exists {
+		return fmt.Errorf("image %s does not exist in ecr", image)
+	}
+
+	return nil
+}
+
+// shortDigest shortens digest for display. e.g. sha256:4c0fdaa8b634
+func shortDigest(digest string) string {
+	if len(digest) > 19 {
+		return digest[:19]
+	}
+	return digest
+}
@@ -0,0 +1,336 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	statePending   = "pending"
+	stateUpdating  = "updating"
+	stateWaiting   = "waiting"
+	stateSucceeded = "succeeded"
+	stateFailed    = "failed"
+	stateTimedOut  = "timed out"
+)
+
+// serviceTarget is a service and the cluster it runs in
+type serviceTarget struct {
+	Cluster string
+	Service string
+}
+
+// ResolveServiceTargets resolves cluster for every service. if cluster is specified, all services are assumed to be in that cluster
+func ResolveServiceTargets(cluster string, services []string) ([]serviceTarget, error) {
+	targets := []serviceTarget{}
+
+	if len(cluster) > 0 {
+		for _, service := range services {
+			targets = append(targets, serviceTarget{cluster, service})
+		}
+		return targets, nil
+	}
+
+	servicesByCluster, err := ecsw.GetServicesByCluster()
+	if err != nil {
+		return targets, err
+	}
+
+	for _, service := range services {
+		found := []string{}
+		for c, names := range servicesByCluster {
+			for _, name := range names {
+				if name == service {
+					found = append(found, c)
+				}
+			}
+		}
+
+		switch len(found) {
+		case 0:
+			return targets, fmt.Errorf("service %s not found in any cluster", service)
+		case 1:
+			targets = append(targets, serviceTarget{found[0], service})
+		default:
+			sort.Strings(found)
+			return targets, fmt.Errorf("service %s found in more than one cluster (%s). you must explicitly specify --cluster argument", service, strings.Join(found, ","))
+		}
+	}
+
+	return targets, nil
+}
+
+// serviceProgress tracks a service while desired count is being changed
+type serviceProgress struct {
+	serviceTarget
+	State        string
+	Detail       string
+	Elapsed      time.Duration
+	DesiredCount int64
+	// Started, TaskDefinition and OldDesiredCount are kept for deployment history
+	Started         time.Time
+	TaskDefinition  string
+	OldDesiredCount int64
+}
+
+// progressBoard renders status of services converging in parallel
+type progressBoard struct {
+	sync.Mutex
+	items []*serviceProgress
+	live  bool
+	lines int
+}
+
+func newProgressBoard(targets []serviceTarget, desiredCount func(t serviceTarget) int64, live bool) *progressBoard {
+	b := &progressBoard{live: live}
+	for _, t := range targets {
+		b.items = append(b.items, &serviceProgress{serviceTarget: t, State: statePending, DesiredCount: desiredCount(t)})
+	}
+	return b
+}
+
+// before keeps the state of service before its desired count is changed
+func (b *progressBoard) before(i int, s ecs.Service, started time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	item := b.items[i]
+	item.Started = started
+	item.TaskDefinition = *s.TaskDefinition
+	item.OldDesiredCount = *s.DesiredCount
+}
+
+func (b *progressBoard) update(i int, state, detail string, elapsed time.Duration) {
+	b.Lock()
+	defer b.Unlock()
+
+	item := b.items[i]
+	changed := item.State != state || item.Detail != detail
+	item.State = state
+	item.Detail = detail
+	item.Elapsed = elapsed
+
+	if b.live {
+		b.render()
+		return
+	}
+
+	// without live table only print state transitions
+	if changed {
+		Log(fmt.Sprintf("%s/%s: %s %s", item.Cluster, item.Service, state, detail))
+	}
+}
+
+// render redraws the table in place. caller must hold the lock
+func (b *progressBoard) render() {
+	var buf bytes.Buffer
+	writeProgressTable(&buf, b.items)
+
+	if b.lines > 0 {
+		// move cursor up and clear previous table
+		fmt.Printf("\033[%dA\033[J", b.lines)
+	}
+	fmt.Print(buf.String())
+	b.lines = strings.Count(buf.String(), "\n")
+}
+
+func writeProgressTable(buf *bytes.Buffer, items []*serviceProgress) {
+	table := tablewriter.NewWriter(buf)
+	table.SetHeader([]string{"Cluster", "Service", "State", "Detail", "Elapsed"})
+	for _, item := range items {
+		table.Append([]string{item.Cluster, item.Service, colorState(item.State), item.Detail, item.Elapsed.Round(time.Second).String()})
+	}
+	table.Render()
+}
+
+func colorState(state string) string {
+	switch state {
+	case stateSucceeded:
+		return green(state)
+	case stateFailed, stateTimedOut:
+		return red(state)
+	default:
+		return state
+	}
+}
+
+// ServicesDesiredCount sets desired count of services concurrently and optionally waits for them to become stable
+func ServicesDesiredCount(targets []serviceTarget, desiredCount int64, concurrency int, wait bool, timeout int64) []*serviceProgress {
+	return ServicesDesiredCounts(targets, func(serviceTarget) int64 { return desiredCount }, concurrency, wait, timeout)
+}
+
+// ServicesDesiredCounts is ServicesDesiredCount with desired count chosen per service
+func ServicesDesiredCounts(targets []serviceTarget, desiredCount func(t serviceTarget) int64, concurrency int, wait bool, timeout int64) []*serviceProgress {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	board := newProgressBoard(targets, desiredCount, wait && _isTerminal)
+	if board.live {
+		Newline()
+		board.Lock()
+		board.render()
+		board.Unlock()
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t serviceTarget) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			state, detail := setDesiredCount(t, board.items[i].DesiredCount, wait, timeout, func(s ecs.Service) {
+				board.before(i, s, start)
+			}, func(state, detail string) {
+				board.update(i, state, detail, time.Since(start))
+			})
+			board.update(i, state, detail, time.Since(start))
+		}(i, t)
+	}
+
+	wg.Wait()
+
+	return board.items
+}
+
+// setDesiredCount updates desired count of one service and reports progress. returns final state
+// before is called with the service as it was described prior to the update
+func setDesiredCount(t serviceTarget, desiredCount int64, wait bool, timeout int64, before func(s ecs.Service), progress func(state, detail string)) (string, string) {
+	progress(stateUpdating, "")
+
+	s, err := describeService(t)
+	if err != nil {
+		return stateFailed, err.Error()
+	}
+	before(s)
+
+	_, err = ecsw.UpdateService(t.Cluster, t.Service, *s.TaskDefinition, desiredCount)
+	if err != nil {
+		return stateFailed, err.Error()
+	}
+
+	if !wait {
+		return stateSucceeded, fmt.Sprintf("desired %d", desiredCount)
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		s, err := describeService(t)
+		if err != nil {
+			return stateFailed, err.Error()
+		}
+
+		detail := fmt.Sprintf("running %d/%d", *s.RunningCount, *s.DesiredCount)
+		if isServiceStable(s) {
+			return stateSucceeded, detail
+		}
+
+		if time.Now().After(deadline) {
+			return stateTimedOut, detail
+		}
+
+		progress(stateWaiting, detail)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func describeService(t serviceTarget) (ecs.Service, error) {
+	result, err := ecsw.DescribeServices(t.Cluster, t.Service)
+	if err != nil {
+		return ecs.Service{}, err
+	}
+	if len(result.Services) == 0 {
+		return ecs.Service{}, errors.New("service not found")
+	}
+	return result.Services[0], nil
+}
+
+// describeAllServices describes every service of every cluster
+func describeAllServices() ([]ecs.Service, error) {
+	services := []ecs.Service{}
+
+	servicesByCluster, err := ecsw.GetServicesByCluster()
+	if err != nil {
+		return services, err
+	}
+
+	for cluster, names := range servicesByCluster {
+		// describe services accepts up to 10 services
+		for start := 0; start < len(names); start += 10 {
+			end := start + 10
+			if end > len(names) {
+				end = len(names)
+			}
+			result, err := ecsw.DescribeServices(cluster, names[start:end]...)
+			if err != nil {
+				return services, err
+			}
+			services = append(services, result.Services...)
+		}
+	}
+
+	return services, nil
+}
+
+// isServiceStable uses the same condition as services stable waiter
+func isServiceStable(s ecs.Service) bool {
+	return len(s.Deployments) == 1 && *s.RunningCount == *s.DesiredCount
+}
+
+// ReportServicesDesiredCount prints final report and exits with non-zero status if any service did not succeed
+func ReportServicesDesiredCount(items []*serviceProgress, action string) {
+	var buf bytes.Buffer
+	writeProgressTable(&buf, items)
+
+	Newline()
+	Print(buf.String())
+
+	failed := []string{}
+	for _, item := range items {
+		if item.State != stateSucceeded {
+			failed = append(failed, item.Service)
+		}
+	}
+
+	if len(failed) > 0 {
+		Failure(fmt.Sprintf("%s failed for %d of %d services: %s", action, len(failed), len(items), strings.Join(failed, ",")))
+		os.Exit(1)
+	}
+
+	Success(fmt.Sprintf("%s %d services", action, len(items)))
+}
@@ -0,0 +1,120 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/placement"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/pflag"
+)
+
+const (
+	maxPlacementConstraints = 10
+	maxPlacementStrategies  = 5
+)
+
+// spreadFields maps shorthand spread fields to ecs placement strategy fields
+var spreadFields = map[string]string{
+	"az":         "attribute:ecs.availability-zone",
+	"instanceId": "instanceId",
+	"host":       "instanceId",
+}
+
+// placementFlags holds flags controlling where ecs places tasks
+type placementFlags struct {
+	constraints      []string
+	distinctInstance bool
+	spread           []string
+	binpack          string
+}
+
+func addPlacementFlags(flags *pflag.FlagSet, p *placementFlags) {
+
+	flags.StringArrayVar(&p.constraints, "placement-constraint", []string{}, `optional: memberOf expression. e.g. "attribute:ecs.instance-type =~ t3.*"`)
+
+	flags.BoolVar(&p.distinctInstance, "distinct-instance", false, "optional: places each task on a different container instance")
+
+	flags.StringArrayVar(&p.spread, "spread", []string{}, "optional: spreads tasks by az, instanceId or attribute:<name>")
+
+	flags.StringVar(&p.binpack, "binpack", "", "optional: binpacks tasks by memory or cpu")
+
+}
+
+// apply validates placement flags and sets them on service options
+func (p *placementFlags) apply(opts *ecsw.ServiceOptions) error {
+
+	for _, expression := range p.constraints {
+		if err := placement.Validate(expression); err != nil {
+			return fmt.Errorf("invalid placement constraint %q: %v", expression, err)
+		}
+		opts.PlacementConstraints = append(opts.PlacementConstraints, ecs.PlacementConstraint{
+			Type:       ecs.PlacementConstraintTypeMemberOf,
+			Expression: aws.String(expression),
+		})
+	}
+
+	if p.distinctInstance {
+		opts.PlacementConstraints = append(opts.PlacementConstraints, ecs.PlacementConstraint{
+			Type: ecs.PlacementConstraintTypeDistinctInstance,
+		})
+	}
+
+	if len(opts.PlacementConstraints) > maxPlacementConstraints {
+		return fmt.Errorf("at most %d placement constraints are allowed", maxPlacementConstraints)
+	}
+
+	// spread first so that binpack only decides among instances that tie on spread
+	for _, field := range p.spread {
+		f, ok := spreadFields[field]
+		if !ok {
+			if !strings.HasPrefix(field, "attribute:") || len(field) == len("attribute:") {
+				return fmt.Errorf("invalid spread field %q. expected az, instanceId or attribute:<name>", field)
+			}
+			f = field
+		}
+		opts.PlacementStrategy = append(opts.PlacementStrategy, ecs.PlacementStrategy{
+			Type:  ecs.PlacementStrategyTypeSpread,
+			Field: aws.String(f),
+		})
+	}
+
+	if len(p.binpack) > 0 {
+		field := strings.ToLower(p.binpack)
+		if field != "memory" && field != "cpu" {
+			return fmt.Errorf("invalid binpack field %q. expected memory or cpu", p.binpack)
+		}
+		opts.PlacementStrategy = append(opts.PlacementStrategy, ecs.PlacementStrategy{
+			Type:  ecs.PlacementStrategyTypeBinpack,
+			Field: aws.String(field),
+		})
+	}
+
+	if len(opts.PlacementStrategy) > maxPlacementStrategies {
+		return fmt.Errorf("at most %d placement strategies are allowed", maxPlacementStrategies)
+	}
+
+	return nil
+}
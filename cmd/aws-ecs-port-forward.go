@@ -0,0 +1,234 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/execw"
+	"github.com/7onetella/morgan/tools/awsapi/ec2w"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsPortForwardCmdCluster string
+var ecsPortForwardCmdTask string
+var ecsPortForwardCmdContainer string
+var ecsPortForwardCmdUser string
+var ecsPortForwardCmdKey string
+var ecsPortForwardCmdBastion string
+var ecsPortForwardCmdBastionKey string
+
+var ecsPortForwardCmd = &cobra.Command{
+	Use:   "port-forward <service> [local-port:]container-port",
+	Short: "Forwards local port to ecs task",
+	Long: `Forwards local port to a task of the service over ssh.
+
+The task's container instance, its private ip and the host port mapped to the container port are looked up
+and ssh is started with local forwarding. The key defaults to ~/.aws/<keyname>.pem where keyname is the key
+pair of the container instance, the same key ec2 attr keyname prints. Use --bastion when the instance is
+not reachable directly. Tasks in awsvpc network mode are forwarded to the task's own ip.
+
+ssh must be on the path. Press ctrl-c to stop forwarding.`,
+	Example: "foo-svc 9000:8080 --bastion ubuntu@bastion.example.com",
+	Args:    cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+		localPort, containerPort, err := parsePortForward(args[1])
+		ExitOn(err)
+
+		cluster := ResolveCluster(ecsPortForwardCmdCluster, service)
+
+		task, err := findPortForwardTask(cluster, service, ecsPortForwardCmdTask)
+		ExitOn(err)
+
+		if task.ContainerInstanceArn == nil {
+			ExitOn(errors.New("task does not run on a container instance"))
+		}
+
+		destination, err := taskDestination(task, ecsPortForwardCmdContainer, containerPort)
+		ExitOn(err)
+
+		result, err := ecsw.DescribeContainerInstances(cluster, *task.ContainerInstanceArn)
+		ExitOnError(err, "describing container instance")
+		if len(result.ContainerInstances) == 0 {
+			ExitOn(errors.New("container instance of task not found"))
+		}
+
+		instance, err := ec2w.DescribeInstance(*result.ContainerInstances[0].Ec2InstanceId)
+		ExitOnError(err, "describing instance")
+
+		key := ecsPortForwardCmdKey
+		if len(key) == 0 {
+			key = filepath.Join(os.Getenv("HOME"), ".aws", aws.StringValue(instance.KeyName)+".pem")
+		}
+
+		sshArgs := []string{"ssh", "-N",
+			"-o", "ExitOnForwardFailure=yes",
+			"-o", "UserKnownHostsFile=/dev/null",
+			"-o", "StrictHostKeyChecking=no",
+			"-i", key,
+			"-L", fmt.Sprintf("%d:%s", localPort, destination),
+		}
+
+		if len(ecsPortForwardCmdBastion) > 0 {
+			bastionKey := ecsPortForwardCmdBastionKey
+			if len(bastionKey) == 0 {
+				bastionKey = key
+			}
+			proxy := fmt.Sprintf("ssh -o UserKnownHostsFile=/dev/null -o StrictHostKeyChecking=no -i %s -W %%h:%%p %s", bastionKey, ecsPortForwardCmdBastion)
+			sshArgs = append(sshArgs, "-o", "ProxyCommand="+proxy)
+		}
+
+		sshArgs = append(sshArgs, ecsPortForwardCmdUser+"@"+aws.StringValue(instance.PrivateIpAddress))
+
+		Info(fmt.Sprintf("forwarding localhost:%d to %s of task %s on %s", localPort, destination, taskID(*task.TaskArn), aws.StringValue(instance.PrivateIpAddress)))
+		Debug(strings.Join(sshArgs, " "))
+
+		err = execw.Run(sshArgs)
+		ExitOnError(err, "forwarding port")
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsPortForwardCmd)
+
+	flags := ecsPortForwardCmd.Flags()
+
+	flags.StringVarP(&ecsPortForwardCmdCluster, "cluster", "c", "", "optional: ecs cluster")
+
+	flags.StringVar(&ecsPortForwardCmdTask, "task", "", "optional: task id. defaults to the first running task")
+
+	flags.StringVar(&ecsPortForwardCmdContainer, "container", "", "optional: container name when more than one container maps the port")
+
+	flags.StringVar(&ecsPortForwardCmdUser, "user", "ec2-user", "optional: ssh user of container instance")
+
+	flags.StringVarP(&ecsPortForwardCmdKey, "key", "i", "", "optional: ssh private key. defaults to ~/.aws/<keyname>.pem")
+
+	flags.StringVar(&ecsPortForwardCmdBastion, "bastion", "", "optional: bastion to jump through. e.g. ubuntu@bastion.example.com")
+
+	flags.StringVar(&ecsPortForwardCmdBastionKey, "bastion-key", "", "optional: ssh private key of bastion. defaults to --key")
+
+}
+
+// parsePortForward parses [local-port:]container-port. local port defaults to container port
+func parsePortForward(spec string) (int64, int64, error) {
+	tokens := strings.Split(spec, ":")
+	if len(tokens) > 2 {
+		return 0, 0, fmt.Errorf("invalid port %s. expected [local-port:]container-port", spec)
+	}
+
+	ports := []int64{}
+	for _, token := range tokens {
+		port, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || port < 1 || port > 65535 {
+			return 0, 0, fmt.Errorf("invalid port %s. expected [local-port:]container-port", spec)
+		}
+		ports = append(ports, port)
+	}
+
+	if len(ports) == 1 {
+		return ports[0], ports[0], nil
+	}
+	return ports[0], ports[1], nil
+}
+
+// findPortForwardTask finds running task of service by id or returns the first running one
+func findPortForwardTask(cluster, service, id string) (ecs.Task, error) {
+	arns, err := ecsw.ListTasks(cluster, service)
+	if err != nil {
+		return ecs.Task{}, err
+	}
+	if len(arns) == 0 {
+		return ecs.Task{}, fmt.Errorf("service %s has no tasks", service)
+	}
+
+	if len(id) > 0 {
+		found := []string{}
+		for _, arn := range arns {
+			if taskID(arn) == id {
+				found = append(found, arn)
+			}
+		}
+		if len(found) == 0 {
+			return ecs.Task{}, fmt.Errorf("task %s not found in service %s", id, service)
+		}
+		arns = found
+	}
+
+	for start := 0; start < len(arns); start += 100 {
+		end := start + 100
+		if end > len(arns) {
+			end = len(arns)
+		}
+		result, err := ecsw.DescribeTasks(cluster, arns[start:end]...)
+		if err != nil {
+			return ecs.Task{}, err
+		}
+		for _, task := range result.Tasks {
+			if aws.StringValue(task.LastStatus) == "RUNNING" {
+				return task, nil
+			}
+		}
+	}
+
+	return ecs.Task{}, fmt.Errorf("no running task of service %s", service)
+}
+
+// taskDestination returns host:port on the container instance the container port is reachable at
+func taskDestination(task ecs.Task, container string, containerPort int64) (string, error) {
+	for _, c := range task.Containers {
+		if len(container) > 0 && aws.StringValue(c.Name) != container {
+			continue
+		}
+		for _, nb := range c.NetworkBindings {
+			if aws.Int64Value(nb.ContainerPort) == containerPort {
+				return fmt.Sprintf("localhost:%d", aws.Int64Value(nb.HostPort)), nil
+			}
+		}
+	}
+
+	// awsvpc tasks have their own network interface and no host port bindings
+	for _, a := range task.Attachments {
+		for _, detail := range a.Details {
+			if aws.StringValue(detail.Name) == "privateIPv4Address" {
+				return fmt.Sprintf("%s:%d", aws.StringValue(detail.Value), containerPort), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("container port %d is not mapped in task %s", containerPort, taskID(*task.TaskArn))
+}
+
+// taskID returns the id at the end of task arn
+func taskID(arn string) string {
+	return arn[strings.LastIndex(arn, "/")+1:]
+}
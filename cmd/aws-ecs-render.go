@@ -0,0 +1,72 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/7onetella/morgan/internal/servicedef"
+	"github.com/spf13/cobra"
+)
+
+var ecsRenderCmdDefinitionOnly bool
+var ecsRenderCmdDefinition definitionFlags
+
+var ecsRenderCmd = &cobra.Command{
+	Use:   "render <service>",
+	Short: "Renders task definition from definition files",
+	Long: `Renders the task definition deploy would register for the environment without calling aws.
+The output can be passed to register-task-definition --cli-input-json or to taskdef lint.`,
+	Example: "foo-svc --env stage --var version=1.0.0",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		def, err := ecsRenderCmdDefinition.load(args[0])
+		ExitOn(err)
+
+		if ecsRenderCmdDefinitionOnly {
+			data, err := servicedef.Marshal(def)
+			ExitOnError(err, "marshalling definition")
+
+			Print(string(data))
+			return
+		}
+
+		sz, err := GetSize(def.Size)
+		ExitOn(err)
+
+		data, err := MarshalTaskDefinition(DefinitionTaskDefinition(def, sz))
+		ExitOnError(err, "marshalling task definition")
+
+		Print(string(data) + "\n")
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsRenderCmd)
+
+	flags := ecsRenderCmd.Flags()
+
+	addDefinitionFlags(flags, &ecsRenderCmdDefinition)
+
+	flags.BoolVar(&ecsRenderCmdDefinitionOnly, "definition", false, "optional: prints merged service definition instead of task definition")
+
+}
@@ -0,0 +1,204 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsRestartCmdCluster string
+var ecsRestartCmdBatch int
+var ecsRestartCmdTimeout int64
+var ecsRestartCmdWaitForServiceStable bool
+
+var ecsRestartCmd = &cobra.Command{
+	Use:   "restart <service names>",
+	Short: "Restarts ecs",
+	Long: `Restarts ecs services without changing the task definition.
+
+By default a new deployment is forced and ecs replaces the tasks according to the deployment configuration
+of the service. With --batch, tasks are stopped a few at a time and the next batch is only stopped once the
+replacements are running and healthy.`,
+	Example: "foo-svc bar-svc --batch 1 --cluster api-cluster",
+	Aliases: []string{"restart-services"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		targets, err := ResolveServiceTargets(ecsRestartCmdCluster, args)
+		ExitOnError(err, "resolving clusters for services")
+
+		for _, t := range targets {
+			if ecsRestartCmdBatch > 0 {
+				err = restartInBatches(t, ecsRestartCmdBatch, ecsRestartCmdTimeout)
+				ExitOnError(err, "restarting service "+t.Service)
+				Success("restarting service " + t.Service)
+				continue
+			}
+
+			s, err := describeService(t)
+			ExitOnError(err, "describing service "+t.Service)
+
+			_, err = ecsw.UpdateServiceWithOptions(t.Cluster, t.Service, *s.TaskDefinition, *s.DesiredCount, ecsw.ServiceOptions{ForceNewDeployment: true})
+			ExitOnError(err, "forcing new deployment of "+t.Service)
+
+			if ecsRestartCmdWaitForServiceStable {
+				err = ecsw.ServiceStable(t.Cluster, t.Service, ecsRestartCmdTimeout)
+				ExitOnError(err, "service stable")
+			}
+
+			Success("restarting service " + t.Service)
+		}
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsRestartCmd)
+
+	flags := ecsRestartCmd.Flags()
+
+	flags.StringVarP(&ecsRestartCmdCluster, "cluster", "c", "", "optional: ecs cluster")
+
+	flags.IntVar(&ecsRestartCmdBatch, "batch", 0, "optional: number of tasks to stop at a time")
+
+	flags.Int64VarP(&ecsRestartCmdTimeout, "timeout", "t", 300, "optional: timeout for service stable or for each batch to be replaced")
+
+	flags.BoolVarP(&ecsRestartCmdWaitForServiceStable, "wait", "w", false, "optional: waits for service to become stable after forcing new deployment")
+
+}
+
+// restartInBatches stops tasks batch at a time and waits for replacements to become healthy before continuing
+func restartInBatches(t serviceTarget, batch int, timeout int64) error {
+	s, err := describeService(t)
+	if err != nil {
+		return err
+	}
+
+	result, err := ecsw.DescribeTaskDefinition(*s.TaskDefinition)
+	if err != nil {
+		return err
+	}
+	healthChecked := hasHealthCheck(result.TaskDefinition)
+
+	original, err := ecsw.ListTasks(t.Cluster, t.Service)
+	if err != nil {
+		return err
+	}
+
+	isOriginal := map[string]bool{}
+	for _, arn := range original {
+		isOriginal[arn] = true
+	}
+
+	replaced := 0
+	for i := 0; i < len(original); i += batch {
+		end := i + batch
+		if end > len(original) {
+			end = len(original)
+		}
+
+		for _, arn := range original[i:end] {
+			Info("stopping task " + parseTaskDefinitionStr(arn))
+			_, err := ecsw.StopTask(t.Cluster, arn, "restarted by morgan")
+			if err != nil {
+				return err
+			}
+		}
+		replaced += end - i
+
+		err = waitForReplacements(t, isOriginal, replaced, *s.DesiredCount, healthChecked, timeout)
+		if err != nil {
+			return err
+		}
+		Info(fmt.Sprintf("%d of %d tasks replaced", replaced, len(original)))
+	}
+
+	return nil
+}
+
+// waitForReplacements waits until number of healthy tasks not in the original set reaches expected count
+func waitForReplacements(t serviceTarget, isOriginal map[string]bool, expected int, desiredCount int64, healthChecked bool, timeout int64) error {
+	// never wait for more replacements than the service is going to run
+	if int64(expected) > desiredCount {
+		expected = int(desiredCount)
+	}
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		arns, err := ecsw.ListTasks(t.Cluster, t.Service)
+		if err != nil {
+			return err
+		}
+
+		healthy := 0
+		for start := 0; start < len(arns); start += 100 {
+			end := start + 100
+			if end > len(arns) {
+				end = len(arns)
+			}
+			result, err := ecsw.DescribeTasks(t.Cluster, arns[start:end]...)
+			if err != nil {
+				return err
+			}
+			for _, task := range result.Tasks {
+				if !isOriginal[*task.TaskArn] && isTaskHealthy(task, healthChecked) {
+					healthy++
+				}
+			}
+		}
+
+		if healthy >= expected {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for replacement tasks. %d of %d healthy", healthy, expected)
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// isTaskHealthy checks task is running and passes container health checks if there are any
+func isTaskHealthy(task ecs.Task, healthChecked bool) bool {
+	if task.LastStatus == nil || *task.LastStatus != "RUNNING" {
+		return false
+	}
+	if healthChecked {
+		return task.HealthStatus == ecs.HealthStatusHealthy
+	}
+	return true
+}
+
+func hasHealthCheck(td *ecs.TaskDefinition) bool {
+	for _, cd := range td.ContainerDefinitions {
+		if cd.HealthCheck != nil && len(cd.HealthCheck.Command) > 0 {
+			return true
+		}
+	}
+	return false
+}
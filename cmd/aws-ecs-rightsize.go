@@ -0,0 +1,323 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/rightsize"
+	"github.com/7onetella/morgan/tools/awsapi/cloudwatchw"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsRightsizeCmdCluster string
+var ecsRightsizeCmdDays int
+var ecsRightsizeCmdPercentile float64
+var ecsRightsizeCmdHeadroom float64
+var ecsRightsizeCmdApply bool
+var ecsRightsizeCmdTimeout int64
+var ecsRightsizeCmdWaitForServiceStable bool
+
+var ecsRightsizeCmd = &cobra.Command{
+	Use:   "rightsize [service names]",
+	Short: "Recommends sizes from cloudwatch metrics",
+	Long: `Recommends the smallest size that fits the usage of services.
+
+CPUUtilization and MemoryUtilization of each service are pulled from cloudwatch over the window.
+The percentile of the utilization is converted to cpu units and MiB of the reserved cpu and memory
+of the task definition, headroom is added, and the smallest size that fits is recommended.
+Sizes include the ones configured in ~/.morgan.yaml.
+
+With --apply, services with a single container are updated with a new task definition revision
+of the recommended size. Without service names, all services of the cluster are checked.`,
+	Example: "foo-svc bar-svc --days 14 --headroom 30 --cluster api-cluster",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		targets, err := rightsizeTargets(ecsRightsizeCmdCluster, args)
+		ExitOnError(err, "resolving services")
+
+		sizes, err := Sizes()
+		ExitOn(err)
+
+		candidates := []rightsize.Size{}
+		for name, s := range sizes {
+			candidates = append(candidates, rightsize.Size{Name: name, CPU: s.CPU, Memory: s.Memory})
+		}
+
+		end := time.Now()
+		start := end.AddDate(0, 0, -ecsRightsizeCmdDays)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Cluster", "Service", "Reserved", "CPU", "Memory", "Recommended", "Note"})
+
+		changes := []rightsizeChange{}
+		for _, t := range targets {
+			s, td := DescribeServiceAndTaskDefinition(t.Cluster, t.Service)
+			reserved := reservedResources(td)
+
+			current := rightsize.Match(candidates, reserved)
+			if len(current) == 0 {
+				current = fmt.Sprintf("%d/%d", reserved.CPU, reserved.Memory)
+			}
+			row := []string{t.Cluster, t.Service, current, "", "", "", ""}
+
+			if reserved.CPU == 0 || reserved.Memory == 0 {
+				row[6] = "no cpu or memory reserved"
+				table.Append(row)
+				continue
+			}
+
+			usage, err := serviceUsage(t, reserved, start, end, ecsRightsizeCmdPercentile)
+			if err != nil {
+				row[6] = err.Error()
+				table.Append(row)
+				continue
+			}
+			row[3] = fmt.Sprintf("%.0f / %d", usage.CPU, reserved.CPU)
+			row[4] = fmt.Sprintf("%.0f / %d", usage.Memory, reserved.Memory)
+
+			recommended, err := rightsize.Recommend(candidates, usage, ecsRightsizeCmdHeadroom)
+			if err != nil {
+				row[6] = err.Error()
+				table.Append(row)
+				continue
+			}
+			row[5] = recommended.Name
+
+			switch {
+			case recommended.CPU == reserved.CPU && recommended.Memory == reserved.Memory:
+				row[5] = green(recommended.Name)
+			case len(td.ContainerDefinitions) != 1:
+				row[6] = "not applied to more than one container"
+			default:
+				note, underProvisioned := rightsizeNote(reserved, recommended, aws.Int64Value(s.DesiredCount))
+				row[6] = note
+				if underProvisioned {
+					row[5] = red(recommended.Name)
+				}
+				changes = append(changes, rightsizeChange{t, recommended.Name, s, td})
+			}
+
+			table.Append(row)
+		}
+
+		table.Render()
+
+		if !ecsRightsizeCmdApply {
+			return
+		}
+
+		for _, c := range changes {
+			c.apply(sizes[c.size], ecsRightsizeCmdWaitForServiceStable, ecsRightsizeCmdTimeout)
+			Success("resizing " + c.target.Service + " to " + c.size)
+		}
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsRightsizeCmd)
+
+	flags := ecsRightsizeCmd.Flags()
+
+	flags.StringVarP(&ecsRightsizeCmdCluster, "cluster", "c", "", "optional: ecs cluster")
+
+	flags.IntVar(&ecsRightsizeCmdDays, "days", 14, "optional: number of days of metrics to look at")
+
+	flags.Float64Var(&ecsRightsizeCmdPercentile, "percentile", 95, "optional: percentile of utilization to size for")
+
+	flags.Float64Var(&ecsRightsizeCmdHeadroom, "headroom", 30, "optional: percent added on top of usage")
+
+	flags.BoolVar(&ecsRightsizeCmdApply, "apply", false, "optional: registers new task definition revisions with recommended sizes and updates services")
+
+	flags.BoolVarP(&ecsRightsizeCmdWaitForServiceStable, "service-stable", "w", false, "optional: waits for services to become stable when applying")
+
+	flags.Int64Var(&ecsRightsizeCmdTimeout, "timeout", 300, "optional: timeout for service stable")
+
+}
+
+// rightsizeChange is a service to be resized
+type rightsizeChange struct {
+	target serviceTarget
+	size   string
+	s      ecs.Service
+	td     *ecs.TaskDefinition
+}
+
+// apply registers task definition with size applied to the only container and updates service to it
+func (c rightsizeChange) apply(sz Size, wait bool, timeout int64) {
+	d := BeginDeployment("rightsize", c.target.Cluster, c.target.Service)
+	d.Before(c.s)
+
+	cd := &c.td.ContainerDefinitions[0]
+	// ulimits of the size replace the current ones instead of being added to them
+	if len(sz.Ulimits) > 0 {
+		cd.Ulimits = nil
+	}
+	sz.apply(cd)
+
+	taskdef := RegisterTaskDefinition(c.td)
+	d.After(taskdef, *c.s.DesiredCount)
+	d.Images = taskDefinitionImages(c.td)
+
+	err := d.Pre()
+	ExitOn(err)
+
+	_, err = ecsw.UpdateService(c.target.Cluster, c.target.Service, taskdef, *c.s.DesiredCount)
+	ExitOnError(err, "updating service "+c.target.Service)
+	d.Changed()
+
+	if wait {
+		err = ecsw.ServiceStable(c.target.Cluster, c.target.Service, timeout)
+		ExitOnError(err, "service stable")
+	}
+
+	d.Finish(nil)
+}
+
+// rightsizeNote describes cpu and memory changes separately. either one going up means the service is under provisioned
+func rightsizeNote(reserved, recommended rightsize.Size, desiredCount int64) (string, bool) {
+	notes := []string{}
+	underProvisioned := false
+
+	switch {
+	case recommended.CPU < reserved.CPU:
+		notes = append(notes, fmt.Sprintf("saves %d cpu units", (reserved.CPU-recommended.CPU)*desiredCount))
+	case recommended.CPU > reserved.CPU:
+		notes = append(notes, fmt.Sprintf("cpu under provisioned by %d units", (recommended.CPU-reserved.CPU)*desiredCount))
+		underProvisioned = true
+	}
+
+	switch {
+	case recommended.Memory < reserved.Memory:
+		notes = append(notes, fmt.Sprintf("saves %d MiB", (reserved.Memory-recommended.Memory)*desiredCount))
+	case recommended.Memory > reserved.Memory:
+		notes = append(notes, fmt.Sprintf("memory under provisioned by %d MiB", (recommended.Memory-reserved.Memory)*desiredCount))
+		underProvisioned = true
+	}
+
+	return fmt.Sprintf("%s over %d tasks", strings.Join(notes, ", "), desiredCount), underProvisioned
+}
+
+// rightsizeTargets returns the specified services or every service of the cluster
+func rightsizeTargets(cluster string, services []string) ([]serviceTarget, error) {
+	if len(services) > 0 {
+		return ResolveServiceTargets(cluster, services)
+	}
+
+	servicesByCluster, err := ecsw.GetServicesByCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []serviceTarget{}
+	for c, names := range servicesByCluster {
+		if len(cluster) > 0 && c != cluster {
+			continue
+		}
+		for _, name := range names {
+			targets = append(targets, serviceTarget{c, name})
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Cluster != targets[j].Cluster {
+			return targets[i].Cluster < targets[j].Cluster
+		}
+		return targets[i].Service < targets[j].Service
+	})
+
+	return targets, nil
+}
+
+// reservedResources returns cpu and memory cloudwatch utilization of the service is relative to.
+// task level values take precedence, otherwise container values are summed up
+func reservedResources(td *ecs.TaskDefinition) rightsize.Size {
+	reserved := rightsize.Size{}
+
+	for _, cd := range td.ContainerDefinitions {
+		reserved.CPU += aws.Int64Value(cd.Cpu)
+		if cd.Memory != nil {
+			reserved.Memory += *cd.Memory
+		} else {
+			reserved.Memory += aws.Int64Value(cd.MemoryReservation)
+		}
+	}
+
+	if cpu, err := strconv.ParseInt(aws.StringValue(td.Cpu), 10, 64); err == nil {
+		reserved.CPU = cpu
+	}
+	if memory, err := strconv.ParseInt(aws.StringValue(td.Memory), 10, 64); err == nil {
+		reserved.Memory = memory
+	}
+
+	return reserved
+}
+
+// serviceUsage returns percentile of cpu and memory used per task
+func serviceUsage(t serviceTarget, reserved rightsize.Size, start, end time.Time, percentile float64) (rightsize.Usage, error) {
+	cpu, err := utilizationPercentile(t, "CPUUtilization", start, end, percentile)
+	if err != nil {
+		return rightsize.Usage{}, err
+	}
+
+	memory, err := utilizationPercentile(t, "MemoryUtilization", start, end, percentile)
+	if err != nil {
+		return rightsize.Usage{}, err
+	}
+
+	return rightsize.UsageOf(reserved, cpu, memory), nil
+}
+
+// utilizationPercentile returns percentile of the per period maximum of metric
+func utilizationPercentile(t serviceTarget, metric string, start, end time.Time, percentile float64) (float64, error) {
+	datapoints, err := cloudwatchw.GetMetricStatistics("AWS/ECS", metric, map[string]string{
+		"ClusterName": t.Cluster,
+		"ServiceName": t.Service,
+	}, start, end, cloudwatchw.Period(start, end), cloudwatch.StatisticMaximum)
+	if err != nil {
+		return 0, err
+	}
+
+	values := []float64{}
+	for _, dp := range datapoints {
+		if dp.Maximum != nil {
+			values = append(values, *dp.Maximum)
+		}
+	}
+
+	if len(values) == 0 {
+		return 0, fmt.Errorf("no %s datapoints", metric)
+	}
+
+	return rightsize.Percentile(values, percentile)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/7onetella/morgan/internal/rightsize"
+)
+
+func TestRightsizeNote(t *testing.T) {
+
+	reserved := rightsize.Size{CPU: 512, Memory: 1024}
+
+	tests := []struct {
+		recommended      rightsize.Size
+		note             string
+		underProvisioned bool
+	}{
+		{rightsize.Size{CPU: 256, Memory: 1024}, "saves 512 cpu units over 2 tasks", false},
+		{rightsize.Size{CPU: 512, Memory: 512}, "saves 1024 MiB over 2 tasks", false},
+		{rightsize.Size{CPU: 1024, Memory: 512}, "cpu under provisioned by 1024 units, saves 1024 MiB over 2 tasks", true},
+		{rightsize.Size{CPU: 256, Memory: 2048}, "saves 512 cpu units, memory under provisioned by 2048 MiB over 2 tasks", true},
+	}
+
+	for _, test := range tests {
+		note, underProvisioned := rightsizeNote(reserved, test.recommended, 2)
+		if note != test.note || underProvisioned != test.underProvisioned {
+			t.Errorf("rightsizeNote(%v) = %q %v, expected %q %v", test.recommended, note, underProvisioned, test.note, test.underProvisioned)
+		}
+	}
+}
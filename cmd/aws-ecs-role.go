@@ -0,0 +1,136 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/iampolicy"
+	"github.com/7onetella/morgan/tools/awsapi/iamw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/pflag"
+)
+
+const ecsTasksPrincipal = "ecs-tasks.amazonaws.com"
+
+const executionRolePolicyArn = "arn:aws:iam::aws:policy/service-role/AmazonECSTaskExecutionRolePolicy"
+
+// roleFlags are task role and execution role of task definition
+type roleFlags struct {
+	taskRole      string
+	executionRole string
+	policies      []string
+}
+
+func addRoleFlags(flags *pflag.FlagSet, r *roleFlags) {
+
+	flags.StringVar(&r.taskRole, "task-role", "", "optional: role name or arn containers assume. created if it does not exist")
+
+	flags.StringVar(&r.executionRole, "execution-role", "", "optional: role name or arn ecs agent uses to pull images and read secrets. created if it does not exist")
+
+	flags.StringArrayVar(&r.policies, "policy", []string{}, "optional: grants task role access. e.g. s3-read:bucket, s3-write:bucket, sqs:queue, sns:topic, dynamodb:table, ssm:/path, secrets:name")
+
+}
+
+func (r roleFlags) isSet() bool {
+	return len(r.taskRole) > 0 || len(r.executionRole) > 0 || len(r.policies) > 0
+}
+
+// apply resolves roles and sets them on task definition. task role defaults to <service>-task when only policies are given
+func (r roleFlags) apply(td *ecs.TaskDefinition, service string) error {
+	document, err := iampolicy.New(r.policies...)
+	if err != nil {
+		return err
+	}
+
+	taskRole := r.taskRole
+	if len(taskRole) == 0 && len(r.policies) > 0 {
+		taskRole = service + "-task"
+	}
+
+	if len(taskRole) > 0 {
+		arn, err := EnsureTaskRole(taskRole, document)
+		if err != nil {
+			return err
+		}
+		td.TaskRoleArn = aws.String(arn)
+	}
+
+	if len(r.executionRole) > 0 {
+		arn, err := EnsureExecutionRole(r.executionRole)
+		if err != nil {
+			return err
+		}
+		td.ExecutionRoleArn = aws.String(arn)
+	}
+
+	return nil
+}
+
+// EnsureTaskRole returns arn of role and creates the role with ecs trust policy if it does not exist.
+// the inline policy <role>-policy belongs to morgan and is put on new and existing roles alike
+func EnsureTaskRole(role string, document iampolicy.Document) (string, error) {
+	if strings.HasPrefix(role, "arn:") {
+		if len(document.Statement) > 0 {
+			return "", errors.New("--policy can not be granted to role given by arn. use role name instead")
+		}
+		return role, nil
+	}
+
+	arn, err := iamw.GetRoleArn(role)
+	if err != nil {
+		return "", err
+	}
+	if len(arn) == 0 {
+		Info("creating role " + role)
+		arn, err = iamw.CreateServiceRole(role, ecsTasksPrincipal, "Task role created by morgan")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if len(document.Statement) > 0 {
+		Info("putting policy " + role + "-policy on role " + role)
+		_, err = iamw.PutRolePolicy(role, role+"-policy", document.String())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return arn, nil
+}
+
+// EnsureExecutionRole returns arn of role and creates the role with the managed execution policy if it does not exist
+func EnsureExecutionRole(role string) (string, error) {
+	if strings.HasPrefix(role, "arn:") {
+		return role, nil
+	}
+
+	arn, err := iamw.GetRoleArn(role)
+	if err != nil || len(arn) > 0 {
+		return arn, err
+	}
+
+	Info("creating role " + role)
+	return iamw.CreateServiceRole(role, ecsTasksPrincipal, "Task execution role created by morgan", executionRolePolicyArn)
+}
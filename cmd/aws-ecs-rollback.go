@@ -0,0 +1,142 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/7onetella/morgan/tools/history"
+	"github.com/spf13/cobra"
+)
+
+var ecsRollbackCmdCluster string
+var ecsRollbackCmdTaskDefinition string
+var ecsRollbackCmdTimeout int64
+var ecsRollbackCmdWaitForServiceStable bool
+
+var ecsRollbackCmd = &cobra.Command{
+	Use:   "rollback <service name>",
+	Short: "Rolls back ecs",
+	Long: `Rolls back ecs service to the task definition it ran before the last deployment.
+
+The previous task definition is looked up in deployment history. If history has no record of the
+current task definition, the previous revision of the task definition family is used.`,
+	Example: "foo-svc --cluster api-cluster",
+	Aliases: []string{"rollback-service"},
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		service := args[0]
+		cluster := ResolveCluster(ecsRollbackCmdCluster, service)
+
+		d := BeginDeployment("rollback", cluster, service)
+
+		s, err := describeService(serviceTarget{cluster, service})
+		ExitOnError(err, "describing service")
+		d.Before(s)
+
+		taskdef := ecsRollbackCmdTaskDefinition
+		if len(taskdef) == 0 {
+			taskdef, err = PreviousTaskDefinition(service, parseTaskDefinitionStr(*s.TaskDefinition))
+			ExitOnError(err, "finding previous task definition")
+		}
+
+		result, err := ecsw.DescribeTaskDefinition(taskdef)
+		ExitOnError(err, "describing task definition "+taskdef)
+
+		d.After(*result.TaskDefinition.TaskDefinitionArn, *s.DesiredCount)
+		d.Images = taskDefinitionImages(result.TaskDefinition)
+
+		err = d.Pre()
+		ExitOn(err)
+
+		_, err = ecsw.UpdateService(cluster, service, *result.TaskDefinition.TaskDefinitionArn, *s.DesiredCount)
+		ExitOnError(err, "updating service")
+		d.Changed()
+
+		if ecsRollbackCmdWaitForServiceStable {
+			err = ecsw.ServiceStable(cluster, service, ecsRollbackCmdTimeout)
+			ExitOnError(err, "service stable")
+		}
+
+		d.Finish(nil)
+
+		Success(fmt.Sprintf("rolling back service to %s", d.NewTaskDefinition))
+
+	},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsRollbackCmd)
+
+	flags := ecsRollbackCmd.Flags()
+
+	flags.StringVarP(&ecsRollbackCmdCluster, "cluster", "c", "", "optional: ecs cluster")
+
+	flags.StringVar(&ecsRollbackCmdTaskDefinition, "to", "", "optional: task definition to roll back to. e.g. family:revision")
+
+	flags.Int64Var(&ecsRollbackCmdTimeout, "timeout", 300, "optional: service stable timeout")
+
+	flags.BoolVarP(&ecsRollbackCmdWaitForServiceStable, "service-stable", "w", false, "optional: waits for service to become stable")
+
+}
+
+// PreviousTaskDefinition finds the task definition service ran before current one was deployed
+func PreviousTaskDefinition(service, current string) (string, error) {
+	ledger, err := HistoryLedger()
+	if err != nil {
+		return "", err
+	}
+
+	records, err := ledger.List(service, 0)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range records {
+		// skip earlier rollbacks so that rolling back twice keeps going back
+		if r.Action == "rollback" || r.Outcome != history.OutcomeSucceeded {
+			continue
+		}
+		if r.NewTaskDefinition == current && len(r.OldTaskDefinition) > 0 && r.OldTaskDefinition != current {
+			return r.OldTaskDefinition, nil
+		}
+	}
+
+	colon := strings.LastIndex(current, ":")
+	if colon < 0 {
+		return "", errors.New("unexpected task definition " + current)
+	}
+	revision, err := strconv.Atoi(current[colon+1:])
+	if err != nil {
+		return "", err
+	}
+	if revision < 2 {
+		return "", errors.New(current + " is the first revision")
+	}
+
+	return current[:colon+1] + strconv.Itoa(revision-1), nil
+}
@@ -0,0 +1,171 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/7onetella/morgan/internal/cron"
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchevents"
+	"github.com/spf13/cobra"
+)
+
+var ecsScheduleCreateCmdCron string
+var ecsScheduleCreateCmdTaskDefinition string
+var ecsScheduleCreateCmdCluster string
+var ecsScheduleCreateCmdEnvVars []string
+var ecsScheduleCreateCmdContainer string
+var ecsScheduleCreateCmdCount int64
+var ecsScheduleCreateCmdRole string
+var ecsScheduleCreateCmdDescription string
+var ecsScheduleCreateCmdPreview int
+var ecsScheduleCreateCmdDryRun bool
+
+var ecsScheduleCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Creates scheduled task",
+	Long: `Creates or updates EventBridge rule that runs the task definition on cron schedule.
+
+The cron expression is validated locally and the next run times are shown before the rule is created.
+EventBridge assumes --role to run the task. If --role is not specified, role ecsEventsRole is used and
+created with AmazonEC2ContainerServiceEventsRole policy if it does not exist.`,
+	Example: `nightly-report --cron "0 3 * * ? *" --task-definition report:12 --cluster batch -e MODE=full`,
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		name := args[0]
+
+		if len(ecsScheduleCreateCmdCron) == 0 {
+			ExitOn(errors.New("--cron is required"))
+		}
+
+		schedule, err := cron.Parse(ecsScheduleCreateCmdCron)
+		ExitOn(err)
+
+		Println(indentation + "next runs of " + schedule.String())
+		for _, run := range nextRuns(ecsScheduleCreateCmdCron, ecsScheduleCreateCmdPreview) {
+			Println(indentation + bullet + run)
+		}
+
+		if ecsScheduleCreateCmdDryRun {
+			return
+		}
+
+		if len(ecsScheduleCreateCmdTaskDefinition) == 0 {
+			ExitOn(errors.New("--task-definition is required"))
+		}
+
+		cluster := ecsScheduleCreateCmdCluster
+		if len(cluster) == 0 {
+			clusters, err := ecsw.ListClusters()
+			ExitOnError(err, "listing clusters")
+			if len(clusters.ClusterArns) != 1 {
+				ExitOn(errors.New("more than one cluster exists. you must explicitly specify --cluster argument"))
+			}
+			cluster = clusters.ClusterArns[0]
+		}
+
+		result, err := ecsw.DescribeClusters(cluster)
+		ExitOnError(err, "describing cluster")
+		if len(result.Clusters) == 0 {
+			ExitOnError(errors.New("cluster not found"), "describing cluster")
+		}
+		clusterArn := *result.Clusters[0].ClusterArn
+
+		result2, err := ecsw.DescribeTaskDefinition(ecsScheduleCreateCmdTaskDefinition)
+		ExitOnError(err, "describing task definition")
+		td := result2.TaskDefinition
+
+		envs := ConvertKeyValuePairArgSliceToMap(ecsScheduleCreateCmdEnvVars)
+		i, err := FindContainerDefinition(td, ecsScheduleCreateCmdContainer, *td.Family)
+		if len(envs) > 0 {
+			ExitOnError(err, "finding container to override environment of")
+		}
+
+		input := ""
+		if len(envs) > 0 {
+			input, err = ScheduleInput(*td.ContainerDefinitions[i].Name, envs)
+			ExitOnError(err, "creating container overrides")
+		}
+
+		roleArn, err := EnsureScheduleRole(ecsScheduleCreateCmdRole)
+		ExitOnError(err, "getting role for EventBridge")
+
+		description := ecsScheduleCreateCmdDescription
+		if len(description) == 0 {
+			description = "runs " + parseTaskDefinitionStr(*td.TaskDefinitionArn) + " on schedule"
+		}
+
+		_, err = eventsw.PutScheduledRule(name, schedule.String(), description)
+		ExitOnError(err, "creating rule")
+
+		target := cloudwatchevents.Target{
+			Id:      aws.String(scheduleTargetID),
+			Arn:     aws.String(clusterArn),
+			RoleArn: aws.String(roleArn),
+			EcsParameters: &cloudwatchevents.EcsParameters{
+				TaskDefinitionArn: td.TaskDefinitionArn,
+				TaskCount:         aws.Int64(ecsScheduleCreateCmdCount),
+				LaunchType:        cloudwatchevents.LaunchTypeEc2,
+			},
+		}
+		if len(input) > 0 {
+			target.Input = aws.String(input)
+		}
+
+		_, err = eventsw.PutTargets(name, target)
+		ExitOnError(err, "adding ecs target to rule")
+
+		Success("creating scheduled task " + name)
+
+	},
+}
+
+func init() {
+
+	ecsScheduleCmd.AddCommand(ecsScheduleCreateCmd)
+
+	flags := ecsScheduleCreateCmd.Flags()
+
+	flags.StringVar(&ecsScheduleCreateCmdCron, "cron", "", `required: cron expression. e.g. "0 3 * * ? *"`)
+
+	flags.StringVar(&ecsScheduleCreateCmdTaskDefinition, "task-definition", "", "required: task definition. e.g. family:revision")
+
+	flags.StringVarP(&ecsScheduleCreateCmdCluster, "cluster", "c", "", "optional: ecs cluster. required if there is more than one cluster")
+
+	flags.StringSliceVarP(&ecsScheduleCreateCmdEnvVars, "env", "e", []string{}, "optional: environment variables to override. e.g. -e key=value")
+
+	flags.StringVar(&ecsScheduleCreateCmdContainer, "container", "", "optional: container to override environment of. defaults to the only container or container named after family")
+
+	flags.Int64Var(&ecsScheduleCreateCmdCount, "count", 1, "optional: number of tasks to run")
+
+	flags.StringVar(&ecsScheduleCreateCmdRole, "role", "", "optional: role name or arn EventBridge assumes to run the task")
+
+	flags.StringVar(&ecsScheduleCreateCmdDescription, "description", "", "optional: rule description")
+
+	flags.IntVar(&ecsScheduleCreateCmdPreview, "preview", 5, "optional: number of next run times to show")
+
+	flags.BoolVar(&ecsScheduleCreateCmdDryRun, "dry-run", false, "optional: only validates cron expression and shows next run times")
+
+}
@@ -0,0 +1,68 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/spf13/cobra"
+)
+
+var ecsScheduleDeleteCmd = &cobra.Command{
+	Use:     "delete <names>",
+	Short:   "Deletes scheduled tasks",
+	Long:    `Deletes scheduled tasks. The ecs target morgan created is removed and the rule is deleted unless it has other targets`,
+	Example: "nightly-report",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		for _, name := range args {
+			// refuse rules morgan did not create so that a typo can not delete an unrelated rule
+			_, err := findScheduleTarget(name)
+			ExitOn(err)
+
+			_, err = eventsw.RemoveTargets(name, scheduleTargetID)
+			ExitOnError(err, "removing target of "+name)
+
+			targets, err := eventsw.ListTargetsByRule(name)
+			ExitOnError(err, "listing targets of "+name)
+
+			if len(targets) > 0 {
+				Info(fmt.Sprintf("keeping rule %s since it has %d other targets", name, len(targets)))
+				Success("deleting scheduled task " + name)
+				continue
+			}
+
+			_, err = eventsw.DeleteRule(name)
+			ExitOnError(err, "deleting "+name)
+
+			Success("deleting " + name)
+		}
+
+	},
+}
+
+func init() {
+
+	ecsScheduleCmd.AddCommand(ecsScheduleDeleteCmd)
+
+}
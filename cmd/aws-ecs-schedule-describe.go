@@ -0,0 +1,94 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsScheduleDescribeCmdShowSecrets bool
+var ecsScheduleDescribeCmdPreview int
+
+var ecsScheduleDescribeCmd = &cobra.Command{
+	Use:     "describe <name>",
+	Short:   "Describes scheduled task",
+	Long:    `Describes scheduled task including environment overrides and next run times`,
+	Example: "nightly-report",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		name := args[0]
+
+		rule, err := eventsw.DescribeRule(name)
+		ExitOnError(err, "describing rule")
+
+		target, err := findScheduleTarget(name)
+		ExitOnError(err, "finding ecs target")
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Value"})
+		table.Append([]string{"Name", name})
+		table.Append([]string{"Description", aws.StringValue(rule.Description)})
+		table.Append([]string{"Schedule", aws.StringValue(rule.ScheduleExpression)})
+		table.Append([]string{"State", colorRuleState(string(rule.State))})
+		table.Append([]string{"Cluster", parseTaskDefinitionStr(aws.StringValue(target.Arn))})
+		table.Append([]string{"Task Definition", parseTaskDefinitionStr(aws.StringValue(target.EcsParameters.TaskDefinitionArn))})
+		table.Append([]string{"Count", fmt.Sprintf("%d", aws.Int64Value(target.EcsParameters.TaskCount))})
+		table.Append([]string{"Role", aws.StringValue(target.RoleArn)})
+
+		for _, o := range parseScheduleInput(target.Input).ContainerOverrides {
+			for _, env := range o.Environment {
+				value := env.Value
+				if !ecsScheduleDescribeCmdShowSecrets {
+					value = MaskEnvValue(env.Name, value)
+				}
+				table.Append([]string{"Env " + o.Name, env.Name + "=" + value})
+			}
+		}
+
+		if rule.State == "ENABLED" {
+			for _, run := range nextRuns(aws.StringValue(rule.ScheduleExpression), ecsScheduleDescribeCmdPreview) {
+				table.Append([]string{"Next Run", run})
+			}
+		}
+
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecsScheduleCmd.AddCommand(ecsScheduleDescribeCmd)
+
+	flags := ecsScheduleDescribeCmd.Flags()
+
+	flags.BoolVar(&ecsScheduleDescribeCmdShowSecrets, "show-secrets", false, "optional: shows values of environment overrides that look like secrets")
+
+	flags.IntVar(&ecsScheduleDescribeCmdPreview, "preview", 5, "optional: number of next run times to show")
+
+}
@@ -0,0 +1,76 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/spf13/cobra"
+)
+
+var ecsScheduleEnableCmd = &cobra.Command{
+	Use:     "enable <names>",
+	Short:   "Enables scheduled tasks",
+	Long:    `Enables scheduled tasks`,
+	Example: "nightly-report",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		for _, name := range args {
+			_, err := findScheduleTarget(name)
+			ExitOn(err)
+
+			_, err = eventsw.EnableRule(name)
+			ExitOnError(err, "enabling "+name)
+
+			Success("enabling " + name)
+		}
+
+	},
+}
+
+var ecsScheduleDisableCmd = &cobra.Command{
+	Use:     "disable <names>",
+	Short:   "Disables scheduled tasks",
+	Long:    `Disables scheduled tasks. The rules are kept and can be enabled again`,
+	Example: "nightly-report",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		for _, name := range args {
+			_, err := findScheduleTarget(name)
+			ExitOn(err)
+
+			_, err = eventsw.DisableRule(name)
+			ExitOnError(err, "disabling "+name)
+
+			Success("disabling " + name)
+		}
+
+	},
+}
+
+func init() {
+
+	ecsScheduleCmd.AddCommand(ecsScheduleEnableCmd)
+
+	ecsScheduleCmd.AddCommand(ecsScheduleDisableCmd)
+
+}
@@ -0,0 +1,87 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsScheduleListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists scheduled tasks",
+	Long:    `Lists EventBridge rules that run ecs tasks on schedule`,
+	Aliases: []string{"ls"},
+	Run: func(cmd *cobra.Command, args []string) {
+
+		rules, err := eventsw.ListRules()
+		ExitOnError(err, "listing rules")
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Name", "Schedule", "State", "Cluster", "Task Definition", "Next Run"})
+
+		for _, rule := range rules {
+			if rule.ScheduleExpression == nil {
+				continue
+			}
+
+			target, err := findScheduleTarget(*rule.Name)
+			if err != nil {
+				continue
+			}
+
+			next := ""
+			if runs := nextRuns(*rule.ScheduleExpression, 1); len(runs) > 0 && rule.State == "ENABLED" {
+				next = runs[0]
+			}
+
+			table.Append([]string{
+				*rule.Name,
+				*rule.ScheduleExpression,
+				colorRuleState(string(rule.State)),
+				parseTaskDefinitionStr(aws.StringValue(target.Arn)),
+				parseTaskDefinitionStr(aws.StringValue(target.EcsParameters.TaskDefinitionArn)),
+				next,
+			})
+		}
+
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecsScheduleCmd.AddCommand(ecsScheduleListCmd)
+
+}
+
+func colorRuleState(state string) string {
+	if strings.ToUpper(state) == "ENABLED" {
+		return green(state)
+	}
+	return red(state)
+}
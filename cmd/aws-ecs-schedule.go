@@ -0,0 +1,166 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/cron"
+	"github.com/7onetella/morgan/tools/awsapi/eventsw"
+	"github.com/7onetella/morgan/tools/awsapi/iamw"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchevents"
+	"github.com/spf13/cobra"
+)
+
+// scheduleTargetID is id of the ecs target morgan puts on schedule rules
+const scheduleTargetID = "morgan-ecs-task"
+
+// scheduleRoleName is the role EventBridge assumes to run tasks. the console creates the same role
+const scheduleRoleName = "ecsEventsRole"
+
+const scheduleRolePolicyArn = "arn:aws:iam::aws:policy/service-role/AmazonEC2ContainerServiceEventsRole"
+
+var ecsScheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Manages scheduled ecs tasks",
+	Long: `Manages tasks run on cron schedule by EventBridge rules.
+
+Cron expressions have six fields and are evaluated in UTC. One of day-of-month and day-of-week must be ?.
+
+minutes hours day-of-month month day-of-week year
+0       3     *            *     ?           *      every day at 03:00
+0/15    *     ?            *     MON-FRI     *      every 15 minutes on weekdays
+0       0     L            *     ?           *      last day of every month`,
+	Aliases: []string{"schedules"},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsScheduleCmd)
+
+}
+
+// taskOverrides is input of ecs target that overrides container environment
+type taskOverrides struct {
+	ContainerOverrides []containerOverride `json:"containerOverrides"`
+}
+
+type containerOverride struct {
+	Name        string           `json:"name"`
+	Environment []overrideEnvVar `json:"environment,omitempty"`
+}
+
+type overrideEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// ScheduleInput returns input json of ecs target that sets environment variables of container
+func ScheduleInput(container string, envs map[string]string) (string, error) {
+	if len(envs) == 0 {
+		return "", nil
+	}
+
+	o := containerOverride{Name: container}
+	for k, v := range envs {
+		o.Environment = append(o.Environment, overrideEnvVar{k, v})
+	}
+	sort.Slice(o.Environment, func(i, j int) bool {
+		return o.Environment[i].Name < o.Environment[j].Name
+	})
+
+	data, err := json.Marshal(taskOverrides{ContainerOverrides: []containerOverride{o}})
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// parseScheduleInput parses input json of ecs target
+func parseScheduleInput(input *string) taskOverrides {
+	o := taskOverrides{}
+	if input != nil {
+		json.Unmarshal([]byte(*input), &o)
+	}
+	return o
+}
+
+// EnsureScheduleRole returns arn of the role EventBridge assumes to run tasks. role can be arn or name. default role is created when missing
+func EnsureScheduleRole(role string) (string, error) {
+	if strings.HasPrefix(role, "arn:") {
+		return role, nil
+	}
+
+	name := role
+	if len(name) == 0 {
+		name = scheduleRoleName
+	}
+
+	arn, err := iamw.GetRoleArn(name)
+	if err != nil || len(arn) > 0 {
+		return arn, err
+	}
+
+	if len(role) > 0 {
+		return "", errors.New("role " + role + " does not exist")
+	}
+
+	Info("creating role " + name)
+	return iamw.CreateServiceRole(name, "events.amazonaws.com", "Allows EventBridge to run ecs tasks", scheduleRolePolicyArn)
+}
+
+// findScheduleTarget finds the ecs target morgan put on rule
+func findScheduleTarget(rule string) (cloudwatchevents.Target, error) {
+	targets, err := eventsw.ListTargetsByRule(rule)
+	if err != nil {
+		return cloudwatchevents.Target{}, err
+	}
+
+	for _, t := range targets {
+		if aws.StringValue(t.Id) == scheduleTargetID && t.EcsParameters != nil {
+			return t, nil
+		}
+	}
+
+	return cloudwatchevents.Target{}, errors.New("rule " + rule + " has no ecs target created by morgan")
+}
+
+// nextRuns returns next run times of schedule expression formatted for display. rate expressions are not previewed
+func nextRuns(expression string, n int) []string {
+	runs := []string{}
+
+	s, err := cron.Parse(expression)
+	if err != nil {
+		return runs
+	}
+
+	for _, t := range s.Next(time.Now(), n) {
+		runs = append(runs, t.Format("2006-01-02 15:04 MST")+"  ("+t.Local().Format("Mon 15:04 MST")+")")
+	}
+
+	return runs
+}
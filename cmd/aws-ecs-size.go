@@ -0,0 +1,230 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/spf13/viper"
+)
+
+// Size is t-shirt sized cpu and memory of a container
+type Size struct {
+	CPU               int64        `mapstructure:"cpu"`
+	Memory            int64        `mapstructure:"memory"`
+	MemoryReservation int64        `mapstructure:"memoryReservation"`
+	Ulimits           []SizeUlimit `mapstructure:"ulimits"`
+}
+
+// SizeUlimit is ulimit applied to containers of a size
+type SizeUlimit struct {
+	Name string `mapstructure:"name"`
+	Soft int64  `mapstructure:"soft"`
+	Hard int64  `mapstructure:"hard"`
+}
+
+// defaultSizes are available without any configuration
+var defaultSizes = map[string]Size{
+	"xsmall":  {CPU: 64, Memory: 128},
+	"small":   {CPU: 128, Memory: 256},
+	"medium":  {CPU: 256, Memory: 512},
+	"large":   {CPU: 512, Memory: 1024},
+	"xlarge":  {CPU: 1024, Memory: 2048},
+	"2xlarge": {CPU: 2048, Memory: 4096},
+}
+
+var ulimitNames = []string{
+	"core", "cpu", "data", "fsize", "locks", "memlock", "msgqueue", "nice",
+	"nofile", "nproc", "rss", "rtprio", "rttime", "sigpending", "stack",
+}
+
+// Sizes returns default sizes overridden and extended by ecs.sizes in ~/.morgan.yaml
+func Sizes() (map[string]Size, error) {
+	sizes := map[string]Size{}
+	for name, size := range defaultSizes {
+		sizes[name] = size
+	}
+
+	configured := map[string]Size{}
+	if err := viper.UnmarshalKey("ecs.sizes", &configured); err != nil {
+		return sizes, err
+	}
+
+	for name, size := range configured {
+		if err := size.validate(); err != nil {
+			return sizes, fmt.Errorf("size %s: %v", name, err)
+		}
+		sizes[strings.ToLower(name)] = size
+	}
+
+	return sizes, nil
+}
+
+// GetSize returns size by name. unknown size is an error listing valid sizes
+func GetSize(name string) (Size, error) {
+	sizes, err := Sizes()
+	if err != nil {
+		return Size{}, err
+	}
+
+	size, ok := sizes[strings.ToLower(name)]
+	if !ok {
+		return Size{}, fmt.Errorf("unknown size %s. valid sizes are %s", name, strings.Join(sortedSizeNames(sizes), ", "))
+	}
+
+	return size, nil
+}
+
+// sortedSizeNames sorts from smallest to largest
+func sortedSizeNames(sizes map[string]Size) []string {
+	names := []string{}
+	for name := range sizes {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := sizes[names[i]], sizes[names[j]]
+		if a.CPU != b.CPU {
+			return a.CPU < b.CPU
+		}
+		if a.Memory != b.Memory {
+			return a.Memory < b.Memory
+		}
+		return names[i] < names[j]
+	})
+
+	return names
+}
+
+func (s Size) validate() error {
+	if s.CPU <= 0 {
+		return errors.New("cpu must be greater than 0")
+	}
+	if s.Memory <= 0 {
+		return errors.New("memory must be greater than 0")
+	}
+	if s.MemoryReservation > s.Memory {
+		return errors.New("memoryReservation can not be greater than memory")
+	}
+
+	for _, u := range s.Ulimits {
+		known := false
+		for _, name := range ulimitNames {
+			if u.Name == name {
+				known = true
+			}
+		}
+		if !known {
+			return fmt.Errorf("unknown ulimit %s", u.Name)
+		}
+		if u.Soft > u.Hard {
+			return fmt.Errorf("soft limit of %s can not be greater than hard limit", u.Name)
+		}
+	}
+
+	return nil
+}
+
+// reservedMemory is the memory ecs reserves on the instance when placing the container
+func (s Size) reservedMemory() int64 {
+	if s.MemoryReservation > 0 {
+		return s.MemoryReservation
+	}
+	return s.Memory
+}
+
+// apply sets memory reservation and ulimits of container
+func (s Size) apply(cd *ecs.ContainerDefinition) {
+	cd.Cpu = aws.Int64(s.CPU)
+	cd.Memory = aws.Int64(s.Memory)
+	cd.MemoryReservation = aws.Int64(s.reservedMemory())
+
+	for _, u := range s.Ulimits {
+		cd.Ulimits = append(cd.Ulimits, ecs.Ulimit{
+			Name:      ecs.UlimitName(u.Name),
+			SoftLimit: aws.Int64(u.Soft),
+			HardLimit: aws.Int64(u.Hard),
+		})
+	}
+}
+
+// CheckCapacity fails fast when none of the active container instances in cluster has enough cpu and memory left for size
+func CheckCapacity(cluster, name string, size Size) error {
+	arns, err := ecsw.ListContainerInstances(cluster)
+	if err != nil {
+		return err
+	}
+
+	if len(arns) == 0 {
+		return fmt.Errorf("cluster %s has no active container instances", cluster)
+	}
+
+	var maxCPU, maxMemory int64
+	for start := 0; start < len(arns); start += 100 {
+		end := start + 100
+		if end > len(arns) {
+			end = len(arns)
+		}
+
+		result, err := ecsw.DescribeContainerInstances(cluster, arns[start:end]...)
+		if err != nil {
+			return err
+		}
+
+		for _, ci := range result.ContainerInstances {
+			cpu, memory := remainingResources(ci)
+			if cpu >= size.CPU && memory >= size.reservedMemory() {
+				return nil
+			}
+			if cpu > maxCPU {
+				maxCPU = cpu
+			}
+			if memory > maxMemory {
+				maxMemory = memory
+			}
+		}
+	}
+
+	return fmt.Errorf("no instance can fit size %s (cpu %d, memory %d). most remaining on any instance is cpu %d, memory %d",
+		name, size.CPU, size.reservedMemory(), maxCPU, maxMemory)
+}
+
+func remainingResources(ci ecs.ContainerInstance) (int64, int64) {
+	var cpu, memory int64
+	for _, r := range ci.RemainingResources {
+		if r.Name == nil || r.IntegerValue == nil {
+			continue
+		}
+		switch *r.Name {
+		case "CPU":
+			cpu = *r.IntegerValue
+		case "MEMORY":
+			memory = *r.IntegerValue
+		}
+	}
+	return cpu, memory
+}
@@ -0,0 +1,84 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/stack"
+	"github.com/spf13/cobra"
+)
+
+var ecsStackDownCmdTimeout int64
+var ecsStackDownCmdParallel int
+
+var ecsStackDownCmd = &cobra.Command{
+	Use:     "down <stack>",
+	Short:   "Stops services of stack in reverse dependency order",
+	Long:    `Stops services of stack tier by tier, dependents first. The next tier is stopped once every service of the tier has stopped`,
+	Example: "dev",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		name := args[0]
+
+		s, tiers, err := GetStack(name)
+		ExitOn(err)
+
+		tiers = stack.Reverse(tiers)
+
+		all := []*serviceProgress{}
+		for i, tier := range tiers {
+			Info(fmt.Sprintf("stopping tier %d of %d: %s", i+1, len(tiers), strings.Join(tier, ",")))
+
+			targets, err := ResolveServiceTargets(s.Cluster, tier)
+			ExitOnError(err, "resolving clusters for services")
+
+			items := ServicesDesiredCount(targets, 0, ecsStackDownCmdParallel, true, ecsStackDownCmdTimeout)
+
+			RecordServicesDesiredCount(items, "stop")
+			all = append(all, items...)
+
+			// stopping dependencies of services that did not stop would break them
+			for _, item := range items {
+				if item.State != stateSucceeded {
+					ReportServicesDesiredCount(all, "stopping stack "+name)
+				}
+			}
+		}
+
+		ReportServicesDesiredCount(all, "stopping stack "+name)
+
+	},
+}
+
+func init() {
+
+	ecsStackCmd.AddCommand(ecsStackDownCmd)
+
+	flags := ecsStackDownCmd.Flags()
+
+	flags.Int64VarP(&ecsStackDownCmdTimeout, "timeout", "t", 300, "optional: timeout for each tier to stop")
+
+	flags.IntVarP(&ecsStackDownCmdParallel, "parallel", "p", 5, "optional: number of services of a tier to stop concurrently")
+
+}
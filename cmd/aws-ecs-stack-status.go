@@ -0,0 +1,93 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/7onetella/morgan/tools/consulapi"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsStackStatusCmd = &cobra.Command{
+	Use:     "status <stack>",
+	Short:   "Shows status of services of stack",
+	Long:    `Shows desired and running counts of services of stack in start order`,
+	Example: "dev",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		s, tiers, err := GetStack(args[0])
+		ExitOn(err)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Tier", "Service", "Cluster", "Desired", "Running", "Consul", "Depends On"})
+
+		for i, tier := range tiers {
+			targets, err := ResolveServiceTargets(s.Cluster, tier)
+			ExitOnError(err, "resolving clusters for services")
+
+			for _, t := range targets {
+				row := []string{fmt.Sprintf("%d", i+1), t.Service, t.Cluster, "", "", "", strings.Join(s.Member(t.Service).DependsOn, ",")}
+
+				svc, err := describeService(t)
+				if err != nil {
+					row[3] = red(err.Error())
+					table.Append(row)
+					continue
+				}
+
+				desired, running := aws.Int64Value(svc.DesiredCount), aws.Int64Value(svc.RunningCount)
+				row[3] = fmt.Sprintf("%d", desired)
+				row[4] = fmt.Sprintf("%d", running)
+				if desired > 0 && running == desired {
+					row[4] = green(row[4])
+				} else if running < desired {
+					row[4] = red(row[4])
+				}
+
+				if consulService := s.Member(t.Service).ConsulService; len(consulService) > 0 {
+					passing, err := consulapi.PassingInstances(consulService, stackConsulAddress(s))
+					if err != nil {
+						row[5] = red("unreachable")
+					} else {
+						row[5] = fmt.Sprintf("%d passing", passing)
+					}
+				}
+
+				table.Append(row)
+			}
+		}
+
+		table.Render()
+
+	},
+}
+
+func init() {
+
+	ecsStackCmd.AddCommand(ecsStackStatusCmd)
+
+}
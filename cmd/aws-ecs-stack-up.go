@@ -0,0 +1,92 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var ecsStackUpCmdTimeout int64
+var ecsStackUpCmdParallel int
+
+var ecsStackUpCmd = &cobra.Command{
+	Use:     "up <stack>",
+	Short:   "Starts services of stack in dependency order",
+	Long:    `Starts services of stack tier by tier. The next tier is started once every service of the tier is stable and healthy`,
+	Example: "dev",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		name := args[0]
+
+		s, tiers, err := GetStack(name)
+		ExitOn(err)
+
+		all := []*serviceProgress{}
+		for i, tier := range tiers {
+			Info(fmt.Sprintf("starting tier %d of %d: %s", i+1, len(tiers), strings.Join(tier, ",")))
+
+			targets, err := ResolveServiceTargets(s.Cluster, tier)
+			ExitOnError(err, "resolving clusters for services")
+
+			items := ServicesDesiredCounts(targets, func(t serviceTarget) int64 {
+				return stackDesiredCount(s, t.Service)
+			}, ecsStackUpCmdParallel, true, ecsStackUpCmdTimeout)
+
+			for _, item := range items {
+				if item.State == stateSucceeded && len(s.Member(item.Service).ConsulService) > 0 {
+					if err := waitForConsulHealthy(s, item.Service, ecsStackUpCmdTimeout); err != nil {
+						item.State = stateFailed
+						item.Detail = err.Error()
+					}
+				}
+			}
+
+			RecordServicesDesiredCount(items, "start")
+			all = append(all, items...)
+
+			// ReportServicesDesiredCount exits if any service of the tier failed so later tiers are not started
+			for _, item := range items {
+				if item.State != stateSucceeded {
+					ReportServicesDesiredCount(all, "starting stack "+name)
+				}
+			}
+		}
+
+		ReportServicesDesiredCount(all, "starting stack "+name)
+
+	},
+}
+
+func init() {
+
+	ecsStackCmd.AddCommand(ecsStackUpCmd)
+
+	flags := ecsStackUpCmd.Flags()
+
+	flags.Int64VarP(&ecsStackUpCmdTimeout, "timeout", "t", 300, "optional: timeout for each tier to become stable and healthy")
+
+	flags.IntVarP(&ecsStackUpCmdParallel, "parallel", "p", 5, "optional: number of services of a tier to start concurrently")
+
+}
@@ -0,0 +1,122 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/7onetella/morgan/internal/stack"
+	"github.com/7onetella/morgan/tools/consulapi"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var ecsStackCmd = &cobra.Command{
+	Use:   "stack",
+	Short: "Starts and stops groups of services in dependency order",
+	Long: `Starts and stops groups of services in dependency order.
+
+Stacks are defined in ~/.morgan.yaml. Services are started tier by tier, each tier once the services it
+depends on are stable and, if consul_service is set, healthy in consul. Services are stopped in reverse.
+
+stacks:
+  dev:
+    cluster: dev-cluster
+    consul_address: 127.0.0.1:8500
+    services:
+      - name: config-svc
+      - name: db-proxy
+        depends_on: [config-svc]
+      - name: foo-api
+        depends_on: [config-svc, db-proxy]
+        desired_count: 2
+        consul_service: foo-api
+      - name: web
+        depends_on: [foo-api]
+
+desired_count defaults to 1. Without cluster, every service is resolved to the cluster it runs in.
+Services are listed with name rather than keyed by name since config keys are not case sensitive.`,
+	Aliases: []string{"stacks"},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsStackCmd)
+
+}
+
+// GetStack reads stack from config and orders its services
+func GetStack(name string) (stack.Stack, [][]string, error) {
+	s := stack.Stack{}
+	if !viper.IsSet("stacks." + name) {
+		return s, nil, fmt.Errorf("stack %s is not defined in config", name)
+	}
+
+	if err := viper.UnmarshalKey("stacks."+name, &s); err != nil {
+		return s, nil, err
+	}
+
+	tiers, err := s.Tiers()
+	if err != nil {
+		return s, nil, fmt.Errorf("stack %s: %v", name, err)
+	}
+
+	return s, tiers, nil
+}
+
+// stackDesiredCount returns desired count of service when stack is up
+func stackDesiredCount(s stack.Stack, service string) int64 {
+	if count := s.Member(service).DesiredCount; count > 0 {
+		return count
+	}
+	return 1
+}
+
+func stackConsulAddress(s stack.Stack) string {
+	if len(s.ConsulAddress) > 0 {
+		return s.ConsulAddress
+	}
+	return "127.0.0.1:8500"
+}
+
+// waitForConsulHealthy waits until the desired count of instances of service pass consul health checks
+func waitForConsulHealthy(s stack.Stack, service string, timeout int64) error {
+	name := s.Member(service).ConsulService
+	expected := int(stackDesiredCount(s, service))
+
+	deadline := time.Now().Add(time.Duration(timeout) * time.Second)
+	for {
+		passing, err := consulapi.PassingInstances(name, stackConsulAddress(s))
+		if err == nil && passing >= expected {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%d of %d instances of %s healthy in consul", passing, expected, name)
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
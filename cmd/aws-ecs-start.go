@@ -21,9 +21,6 @@
 package cmd
 
 import (
-	"errors"
-
-	"github.com/7onetella/morgan/tools/awsapi/ecsw"
 	"github.com/spf13/cobra"
 )
 
@@ -31,49 +28,29 @@ var ecsStartCmdCluster string
 var ecsStartCmdTimeout int64
 var ecsStartCmdDesiredCount int64
 var ecsStartCmdWaitForServiceStable bool
+var ecsStartCmdParallel int
 
 var ecsStartCmd = &cobra.Command{
-	Use:     "start <service names>",
-	Short:   "Starts ecs",
-	Long:    `Starts ecs`,
+	Use:   "start <service names>",
+	Short: "Starts ecs",
+	Long: `Starts ecs services concurrently.
+
+Every service is resolved to its own cluster unless --cluster is specified. With --service-stable
+a live status table is shown while services converge and the command exits with non-zero status
+if any of the services failed or timed out.`,
 	Example: "foo-svc -c api-cluster",
 	Aliases: []string{"start-services"},
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		cluster := ecsStartCmdCluster
-		service := args[0]
-		taskdef := ""
-
-		// if cluster is not specified, then assume there is only one cluster and use that cluster
-		if len(cluster) == 0 {
-			clusters := GetClustersForService(service)
-
-			CheckForClusterAmbiguity(clusters)
+		targets, err := ResolveServiceTargets(ecsStartCmdCluster, args)
+		ExitOnError(err, "resolving clusters for services")
 
-			cluster = GetClusterForService(clusters, service)
-		}
+		items := ServicesDesiredCount(targets, ecsStartCmdDesiredCount, ecsStartCmdParallel, ecsStartCmdWaitForServiceStable, ecsStartCmdTimeout)
 
-		services := args[0:]
+		RecordServicesDesiredCount(items, "start")
 
-		for _, svc := range services {
-			result, err := ecsw.DescribeServices(cluster, svc)
-			ExitOnError(err, "describing services")
-			if len(result.Services) == 0 {
-				ExitOnError(errors.New("search result count 0"), "finding service")
-			}
-			taskdef = *result.Services[0].TaskDefinition
-
-			_, err = ecsw.UpdateService(cluster, svc, taskdef, ecsStartCmdDesiredCount)
-			ExitOnError(err, "updating service with specified desired count")
-
-			if ecsStartCmdWaitForServiceStable {
-				err = ecsw.ServiceStable(cluster, svc, ecsStartCmdTimeout)
-				ExitOnError(err, "service stable")
-			}
-
-			Success("starting service " + svc)
-		}
+		ReportServicesDesiredCount(items, "starting")
 
 	},
 }
@@ -92,4 +69,6 @@ func init() {
 
 	flags.BoolVarP(&ecsStartCmdWaitForServiceStable, "service-stable", "w", false, "waits for service to become stable")
 
+	flags.IntVarP(&ecsStartCmdParallel, "parallel", "p", 5, "number of services to start concurrently")
+
 }
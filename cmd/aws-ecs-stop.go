@@ -21,58 +21,35 @@
 package cmd
 
 import (
-	"errors"
-
-	"github.com/7onetella/morgan/tools/awsapi/ecsw"
 	"github.com/spf13/cobra"
 )
 
 var ecsStopCmdCluster string
 var ecsStopCmdTimeout int64
 var ecsStopCmdWaitForServiceStable bool
+var ecsStopCmdParallel int
 
 var ecsStopCmd = &cobra.Command{
-	Use:     "stop <service name>",
-	Short:   "Stops ecs",
-	Long:    `Stops ecs`,
+	Use:   "stop <service name>",
+	Short: "Stops ecs",
+	Long: `Stops ecs services concurrently.
+
+Every service is resolved to its own cluster unless --cluster is specified. With --service-stable
+a live status table is shown while services converge and the command exits with non-zero status
+if any of the services failed or timed out.`,
 	Example: "foo-svc -c api-cluster",
 	Aliases: []string{"stop-services"},
 	Args:    cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		cluster := ecsStopCmdCluster
-		service := args[0]
-		taskdef := ""
-
-		// if cluster is not specified, then assume there is only one cluster and use that cluster
-		if len(cluster) == 0 {
-			clusters := GetClustersForService(service)
-
-			CheckForClusterAmbiguity(clusters)
+		targets, err := ResolveServiceTargets(ecsStopCmdCluster, args)
+		ExitOnError(err, "resolving clusters for services")
 
-			cluster = GetClusterForService(clusters, service)
-		}
+		items := ServicesDesiredCount(targets, 0, ecsStopCmdParallel, ecsStopCmdWaitForServiceStable, ecsStopCmdTimeout)
 
-		services := args[0:]
+		RecordServicesDesiredCount(items, "stop")
 
-		for _, svc := range services {
-			result, err := ecsw.DescribeServices(cluster, svc)
-			ExitOnError(err, "describing services")
-			if len(result.Services) == 0 {
-				ExitOnError(errors.New("search result count 0"), "finding service")
-			}
-			taskdef = *result.Services[0].TaskDefinition
-
-			_, err = ecsw.UpdateService(cluster, svc, taskdef, 0)
-			ExitOnError(err, "updating service with desired count of 0")
-
-			if ecsStopCmdWaitForServiceStable {
-				err = ecsw.ServiceStable(cluster, svc, ecsStopCmdTimeout)
-				ExitOnError(err, "service stable")
-			}
-
-			Success("stopping service " + svc)
-		}
+		ReportServicesDesiredCount(items, "stopping")
 
 	},
 }
@@ -89,4 +66,6 @@ func init() {
 
 	flags.BoolVarP(&ecsStopCmdWaitForServiceStable, "service-stable", "w", false, "waits for service to become stable")
 
+	flags.IntVarP(&ecsStopCmdParallel, "parallel", "p", 5, "number of services to stop concurrently")
+
 }
@@ -0,0 +1,182 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+)
+
+var volumeNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,255}$`)
+
+// defaultTmpfsSize is tmpfs size in MiB when size is not specified
+const defaultTmpfsSize = 64
+
+// storageFlags holds volume related flags of ecs create
+type storageFlags struct {
+	volumes []string
+	mounts  []string
+	tmpfs   []string
+}
+
+// isSet checks to see if any storage flag is specified
+func (f storageFlags) isSet() bool {
+	return len(f.volumes) > 0 || len(f.mounts) > 0 || len(f.tmpfs) > 0
+}
+
+// apply adds volumes to task definition and mount points and tmpfs to the container definition
+func (f storageFlags) apply(td *ecs.TaskDefinition, cd *ecs.ContainerDefinition) error {
+	volumes := map[string]bool{}
+
+	for _, v := range f.volumes {
+		volume, err := ParseVolume(v)
+		if err != nil {
+			return err
+		}
+		if volumes[*volume.Name] {
+			return fmt.Errorf("volume %s is defined more than once", *volume.Name)
+		}
+		volumes[*volume.Name] = true
+		td.Volumes = append(td.Volumes, volume)
+	}
+
+	mounted := map[string]bool{}
+	for _, m := range f.mounts {
+		mp, err := ParseMountPoint(m)
+		if err != nil {
+			return err
+		}
+		if !volumes[*mp.SourceVolume] {
+			return fmt.Errorf("mount %s refers to undefined volume %s. define it with --volume", m, *mp.SourceVolume)
+		}
+		mounted[*mp.SourceVolume] = true
+		cd.MountPoints = append(cd.MountPoints, mp)
+	}
+
+	for name := range volumes {
+		if !mounted[name] {
+			Info("volume " + name + " is not mounted by the container")
+		}
+	}
+
+	for _, t := range f.tmpfs {
+		tmpfs, err := ParseTmpfs(t)
+		if err != nil {
+			return err
+		}
+		if cd.LinuxParameters == nil {
+			cd.LinuxParameters = &ecs.LinuxParameters{}
+		}
+		cd.LinuxParameters.Tmpfs = append(cd.LinuxParameters.Tmpfs, tmpfs)
+	}
+
+	return nil
+}
+
+// ParseVolume parses name:/host/path into host volume. name only results in docker volume that survives task restarts
+func ParseVolume(s string) (ecs.Volume, error) {
+	tokens := strings.SplitN(strings.TrimSpace(s), ":", 2)
+	name := tokens[0]
+
+	if !volumeNameRegexp.MatchString(name) {
+		return ecs.Volume{}, fmt.Errorf("invalid volume name %q. use letters, numbers, hyphens and underscores", name)
+	}
+
+	if len(tokens) == 1 {
+		return ecs.Volume{
+			Name: aws.String(name),
+			DockerVolumeConfiguration: &ecs.DockerVolumeConfiguration{
+				Scope:         ecs.ScopeShared,
+				Autoprovision: aws.Bool(true),
+				Driver:        aws.String("local"),
+			},
+		}, nil
+	}
+
+	path := tokens[1]
+	if !strings.HasPrefix(path, "/") {
+		return ecs.Volume{}, fmt.Errorf("host path of volume %s must be absolute but was %q", name, path)
+	}
+
+	return ecs.Volume{
+		Name: aws.String(name),
+		Host: &ecs.HostVolumeProperties{
+			SourcePath: aws.String(path),
+		},
+	}, nil
+}
+
+// ParseMountPoint parses name:/container/path[:ro] into mount point
+func ParseMountPoint(s string) (ecs.MountPoint, error) {
+	tokens := strings.Split(strings.TrimSpace(s), ":")
+	if len(tokens) < 2 || len(tokens) > 3 {
+		return ecs.MountPoint{}, fmt.Errorf("invalid mount %q. expected name:/container/path[:ro]", s)
+	}
+
+	if !strings.HasPrefix(tokens[1], "/") {
+		return ecs.MountPoint{}, fmt.Errorf("container path of mount %s must be absolute", s)
+	}
+
+	readOnly := false
+	if len(tokens) == 3 {
+		switch tokens[2] {
+		case "ro":
+			readOnly = true
+		case "rw":
+		default:
+			return ecs.MountPoint{}, fmt.Errorf("invalid mount mode %q. expected ro or rw", tokens[2])
+		}
+	}
+
+	return ecs.MountPoint{
+		SourceVolume:  aws.String(tokens[0]),
+		ContainerPath: aws.String(tokens[1]),
+		ReadOnly:      aws.Bool(readOnly),
+	}, nil
+}
+
+// ParseTmpfs parses /container/path[:size] into tmpfs mount. size is in MiB
+func ParseTmpfs(s string) (ecs.Tmpfs, error) {
+	tokens := strings.SplitN(strings.TrimSpace(s), ":", 2)
+
+	if !strings.HasPrefix(tokens[0], "/") {
+		return ecs.Tmpfs{}, fmt.Errorf("tmpfs path must be absolute but was %q", tokens[0])
+	}
+
+	size := int64(defaultTmpfsSize)
+	if len(tokens) == 2 {
+		v, err := strconv.ParseInt(strings.TrimSuffix(strings.ToLower(tokens[1]), "m"), 10, 64)
+		if err != nil || v <= 0 {
+			return ecs.Tmpfs{}, fmt.Errorf("invalid tmpfs size %q. expected size in MiB", tokens[1])
+		}
+		size = v
+	}
+
+	return ecs.Tmpfs{
+		ContainerPath: aws.String(tokens[0]),
+		Size:          aws.Int64(size),
+	}, nil
+}
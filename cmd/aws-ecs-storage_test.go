@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestParseVolume(t *testing.T) {
+
+	tests := []struct {
+		in     string
+		docker bool
+		path   string
+		valid  bool
+	}{
+		{"data:/var/lib/redis", false, "/var/lib/redis", true},
+		{"pgdata", true, "", true},
+		{" cache_1 ", true, "", true},
+		{"data:relative/path", false, "", false},
+		{"bad name:/data", false, "", false},
+		{":/data", false, "", false},
+	}
+
+	for _, test := range tests {
+		v, err := ParseVolume(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseVolume(%q) error = %v", test.in, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if test.docker && (v.DockerVolumeConfiguration == nil || v.Host != nil) {
+			t.Errorf("ParseVolume(%q) should be docker volume", test.in)
+		}
+		if !test.docker && (v.Host == nil || aws.StringValue(v.Host.SourcePath) != test.path) {
+			t.Errorf("ParseVolume(%q) should be host volume at %s", test.in, test.path)
+		}
+	}
+}
+
+func TestParseMountPoint(t *testing.T) {
+
+	tests := []struct {
+		in       string
+		volume   string
+		path     string
+		readOnly bool
+		valid    bool
+	}{
+		{"data:/data", "data", "/data", false, true},
+		{"shared:/shared:ro", "shared", "/shared", true, true},
+		{"shared:/shared:rw", "shared", "/shared", false, true},
+		{"data", "", "", false, false},
+		{"data:data", "", "", false, false},
+		{"data:/data:rx", "", "", false, false},
+		{"data:/data:ro:extra", "", "", false, false},
+	}
+
+	for _, test := range tests {
+		mp, err := ParseMountPoint(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseMountPoint(%q) error = %v", test.in, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if aws.StringValue(mp.SourceVolume) != test.volume || aws.StringValue(mp.ContainerPath) != test.path || aws.BoolValue(mp.ReadOnly) != test.readOnly {
+			t.Errorf("ParseMountPoint(%q) = %s %s %v", test.in, aws.StringValue(mp.SourceVolume), aws.StringValue(mp.ContainerPath), aws.BoolValue(mp.ReadOnly))
+		}
+	}
+}
+
+func TestParseTmpfs(t *testing.T) {
+
+	tests := []struct {
+		in    string
+		path  string
+		size  int64
+		valid bool
+	}{
+		{"/tmp", "/tmp", defaultTmpfsSize, true},
+		{"/tmp:128", "/tmp", 128, true},
+		{"/tmp:256m", "/tmp", 256, true},
+		{"tmp:128", "", 0, false},
+		{"/tmp:0", "", 0, false},
+		{"/tmp:big", "", 0, false},
+	}
+
+	for _, test := range tests {
+		tmpfs, err := ParseTmpfs(test.in)
+		if (err == nil) != test.valid {
+			t.Errorf("ParseTmpfs(%q) error = %v", test.in, err)
+			continue
+		}
+		if !test.valid {
+			continue
+		}
+		if aws.StringValue(tmpfs.ContainerPath) != test.path || aws.Int64Value(tmpfs.Size) != test.size {
+			t.Errorf("ParseTmpfs(%q) = %s %d", test.in, aws.StringValue(tmpfs.ContainerPath), aws.Int64Value(tmpfs.Size))
+		}
+	}
+}
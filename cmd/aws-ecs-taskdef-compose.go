@@ -0,0 +1,574 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/7onetella/morgan/internal/compose"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ecsTaskdefToComposeCmdCluster string
+var ecsTaskdefToComposeCmdOutput string
+
+var ecsTaskdefToComposeCmd = &cobra.Command{
+	Use:   "to-compose <family[:revision]|service|file>",
+	Short: "Converts task definition to docker-compose",
+	Long: `Converts task definition to docker-compose file so that the service can be run locally.
+
+Images, environment, ports, volumes, tmpfs, health checks, links and resources are converted. Fields that
+have no compose equivalent such as secrets and log configuration are reported on stderr.`,
+	Example: "foo-svc -o docker-compose.yml",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		td, err := LoadTaskDefinitionOrService(args[0], ecsTaskdefToComposeCmdCluster)
+		ExitOnError(err, "reading task definition")
+
+		f, notes := TaskDefinitionToCompose(td)
+
+		data, err := f.Marshal()
+		ExitOnError(err, "marshalling docker-compose")
+
+		for _, note := range notes {
+			fmt.Fprintln(os.Stderr, "# "+note)
+		}
+
+		if len(ecsTaskdefToComposeCmdOutput) == 0 {
+			fmt.Print(string(data))
+			return
+		}
+
+		err = ioutil.WriteFile(ecsTaskdefToComposeCmdOutput, data, 0644)
+		ExitOnError(err, "writing "+ecsTaskdefToComposeCmdOutput)
+
+		Success("writing " + ecsTaskdefToComposeCmdOutput)
+
+	},
+}
+
+var ecsTaskdefFromComposeCmdFile string
+var ecsTaskdefFromComposeCmdFamily string
+var ecsTaskdefFromComposeCmdSize string
+var ecsTaskdefFromComposeCmdDryRun bool
+
+var ecsTaskdefFromComposeCmd = &cobra.Command{
+	Use:   "from-compose",
+	Short: "Registers task definitions from docker-compose",
+	Long: `Registers task definitions from docker-compose file.
+
+Every compose service becomes its own task definition named after the service. With --family all services are
+put in a single task definition so that links and depends_on keep working. Containers without memory limit or
+reservation get the cpu and memory of --size. Fields that are not supported are reported.`,
+	Example: "-f docker-compose.yml --family hello-world --dry-run",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+
+		data, err := ioutil.ReadFile(ecsTaskdefFromComposeCmdFile)
+		ExitOnError(err, "reading "+ecsTaskdefFromComposeCmdFile)
+
+		f, err := compose.Parse(data)
+		ExitOnError(err, "parsing "+ecsTaskdefFromComposeCmdFile)
+
+		size, err := GetSize(ecsTaskdefFromComposeCmdSize)
+		ExitOn(err)
+
+		tds, notes, err := ComposeToTaskDefinitions(f, ecsTaskdefFromComposeCmdFamily, size)
+		ExitOn(err)
+
+		for _, note := range notes {
+			Println(indentation + bullet + note)
+		}
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Family", "Container", "Image", "CPU", "Memory", "Ports"})
+		for _, td := range tds {
+			family := *td.Family
+			if !ecsTaskdefFromComposeCmdDryRun {
+				family = parseTaskDefinitionStr(RegisterTaskDefinition(td))
+			}
+			for _, cd := range td.ContainerDefinitions {
+				ports := []string{}
+				for _, pm := range cd.PortMappings {
+					ports = append(ports, compose.Port{HostPort: aws.Int64Value(pm.HostPort), ContainerPort: aws.Int64Value(pm.ContainerPort), Protocol: string(pm.Protocol)}.String())
+				}
+				table.Append([]string{family, *cd.Name, *cd.Image, fmt.Sprintf("%d", aws.Int64Value(cd.Cpu)), formatMemory(cd), strings.Join(ports, ",")})
+			}
+		}
+		table.Render()
+
+		if ecsTaskdefFromComposeCmdDryRun {
+			Info("dry run. task definitions were not registered")
+			return
+		}
+
+		Success(fmt.Sprintf("registering %d task definitions", len(tds)))
+
+	},
+}
+
+func init() {
+
+	ecsTaskdefCmd.AddCommand(ecsTaskdefToComposeCmd)
+
+	flags := ecsTaskdefToComposeCmd.Flags()
+
+	flags.StringVarP(&ecsTaskdefToComposeCmdCluster, "cluster", "c", "", "optional: ecs cluster of the service")
+
+	flags.StringVarP(&ecsTaskdefToComposeCmdOutput, "output", "o", "", "optional: file to write to. defaults to stdout")
+
+	ecsTaskdefCmd.AddCommand(ecsTaskdefFromComposeCmd)
+
+	flags = ecsTaskdefFromComposeCmd.Flags()
+
+	flags.StringVarP(&ecsTaskdefFromComposeCmdFile, "file", "f", "docker-compose.yml", "optional: docker-compose file")
+
+	flags.StringVar(&ecsTaskdefFromComposeCmdFamily, "family", "", "optional: registers all services as containers of a single task definition")
+
+	flags.StringVar(&ecsTaskdefFromComposeCmdSize, "size", "small", "optional: size of containers without memory limit or reservation")
+
+	flags.BoolVar(&ecsTaskdefFromComposeCmdDryRun, "dry-run", false, "optional: shows task definitions without registering them")
+
+}
+
+func formatMemory(cd ecs.ContainerDefinition) string {
+	memory := ""
+	if cd.MemoryReservation != nil {
+		memory = toString(cd.MemoryReservation)
+	}
+	if cd.Memory != nil {
+		memory = strings.TrimPrefix(memory+"/"+toString(cd.Memory), "/")
+	}
+	return memory
+}
+
+// TaskDefinitionToCompose converts containers of task definition to compose services. notes describe what was not converted
+func TaskDefinitionToCompose(td *ecs.TaskDefinition) (*compose.File, []string) {
+	f := &compose.File{Version: "3.7", Services: map[string]compose.Service{}}
+	notes := []string{}
+
+	volumes := map[string]ecs.Volume{}
+	for _, v := range td.Volumes {
+		volumes[aws.StringValue(v.Name)] = v
+	}
+
+	for _, cd := range td.ContainerDefinitions {
+		name := aws.StringValue(cd.Name)
+
+		s := compose.Service{
+			Image:          aws.StringValue(cd.Image),
+			Command:        cd.Command,
+			Entrypoint:     cd.EntryPoint,
+			Links:          cd.Links,
+			WorkingDir:     aws.StringValue(cd.WorkingDirectory),
+			User:           aws.StringValue(cd.User),
+			Hostname:       aws.StringValue(cd.Hostname),
+			Privileged:     aws.BoolValue(cd.Privileged),
+			CPUShares:      aws.Int64Value(cd.Cpu),
+			MemLimit:       formatComposeMemory(cd.Memory),
+			MemReservation: formatComposeMemory(cd.MemoryReservation),
+		}
+
+		if len(cd.Environment) > 0 {
+			s.Environment = compose.Environment{}
+			for _, env := range cd.Environment {
+				s.Environment[aws.StringValue(env.Name)] = aws.StringValue(env.Value)
+			}
+		}
+
+		for _, pm := range cd.PortMappings {
+			p := compose.Port{HostPort: aws.Int64Value(pm.HostPort), ContainerPort: aws.Int64Value(pm.ContainerPort), Protocol: string(pm.Protocol)}
+			// dynamic host port is published on the same port locally
+			if p.HostPort == 0 {
+				p.HostPort = p.ContainerPort
+			}
+			s.Ports = append(s.Ports, p.String())
+		}
+
+		for _, mp := range cd.MountPoints {
+			source := aws.StringValue(mp.SourceVolume)
+			m := compose.Mount{Source: source, Target: aws.StringValue(mp.ContainerPath), ReadOnly: aws.BoolValue(mp.ReadOnly)}
+
+			v := volumes[source]
+			switch {
+			case v.Host != nil && v.Host.SourcePath != nil:
+				m.Source = *v.Host.SourcePath
+			case v.DockerVolumeConfiguration != nil:
+				addComposeVolume(f, source, &compose.Volume{Driver: aws.StringValue(v.DockerVolumeConfiguration.Driver)})
+			default:
+				addComposeVolume(f, source, nil)
+			}
+
+			s.Volumes = append(s.Volumes, m.String())
+		}
+
+		if len(cd.VolumesFrom) > 0 {
+			notes = append(notes, name+": volumesFrom is not supported")
+		}
+
+		if cd.LinuxParameters != nil {
+			for _, t := range cd.LinuxParameters.Tmpfs {
+				s.Tmpfs = append(s.Tmpfs, fmt.Sprintf("%s:size=%dm", aws.StringValue(t.ContainerPath), aws.Int64Value(t.Size)))
+			}
+		}
+
+		if cd.HealthCheck != nil && len(cd.HealthCheck.Command) > 0 {
+			s.Healthcheck = &compose.Healthcheck{
+				Test:    cd.HealthCheck.Command,
+				Retries: aws.Int64Value(cd.HealthCheck.Retries),
+			}
+			if cd.HealthCheck.Interval != nil {
+				s.Healthcheck.Interval = compose.FormatSeconds(*cd.HealthCheck.Interval)
+			}
+			if cd.HealthCheck.Timeout != nil {
+				s.Healthcheck.Timeout = compose.FormatSeconds(*cd.HealthCheck.Timeout)
+			}
+			if cd.HealthCheck.StartPeriod != nil {
+				s.Healthcheck.StartPeriod = compose.FormatSeconds(*cd.HealthCheck.StartPeriod)
+			}
+		}
+
+		for _, dep := range cd.DependsOn {
+			s.DependsOn = append(s.DependsOn, aws.StringValue(dep.ContainerName))
+		}
+
+		for _, secret := range cd.Secrets {
+			notes = append(notes, fmt.Sprintf("%s: secret %s from %s is not converted. set it in .env", name, aws.StringValue(secret.Name), aws.StringValue(secret.ValueFrom)))
+		}
+
+		if cd.LogConfiguration != nil {
+			notes = append(notes, fmt.Sprintf("%s: %s log configuration is dropped", name, cd.LogConfiguration.LogDriver))
+		}
+
+		f.Services[name] = s
+	}
+
+	return f, notes
+}
+
+func addComposeVolume(f *compose.File, name string, v *compose.Volume) {
+	if f.Volumes == nil {
+		f.Volumes = map[string]*compose.Volume{}
+	}
+	f.Volumes[name] = v
+}
+
+func formatComposeMemory(mib *int64) string {
+	if mib == nil {
+		return ""
+	}
+	return compose.FormatMemory(*mib)
+}
+
+var nonVolumeNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// ComposeToTaskDefinitions converts compose services to task definitions. if family is specified, all services become
+// containers of a single task definition. notes describe what was not converted
+func ComposeToTaskDefinitions(f *compose.File, family string, size Size) ([]*ecs.TaskDefinition, []string, error) {
+	notes := f.Unsupported()
+	tds := []*ecs.TaskDefinition{}
+
+	groups := [][]string{}
+	if len(family) > 0 {
+		groups = append(groups, f.ServiceNames())
+	} else {
+		for _, name := range f.ServiceNames() {
+			groups = append(groups, []string{name})
+		}
+	}
+
+	for _, group := range groups {
+		td := &ecs.TaskDefinition{Family: aws.String(family)}
+		if len(family) == 0 {
+			td.Family = aws.String(group[0])
+		}
+
+		inTask := map[string]bool{}
+		for _, name := range group {
+			inTask[name] = true
+		}
+
+		for _, name := range group {
+			cd, n, err := composeContainerDefinition(f, name, td, inTask, size)
+			if err != nil {
+				return tds, notes, err
+			}
+			notes = append(notes, n...)
+			td.ContainerDefinitions = append(td.ContainerDefinitions, cd)
+		}
+
+		tds = append(tds, td)
+	}
+
+	return tds, notes, nil
+}
+
+func composeContainerDefinition(f *compose.File, name string, td *ecs.TaskDefinition, inTask map[string]bool, size Size) (ecs.ContainerDefinition, []string, error) {
+	s := f.Services[name]
+	notes := []string{}
+	note := func(format string, a ...interface{}) {
+		notes = append(notes, "service "+name+": "+fmt.Sprintf(format, a...))
+	}
+
+	if len(s.Image) == 0 {
+		return ecs.ContainerDefinition{}, notes, fmt.Errorf("service %s has no image. images must be built and pushed before registering", name)
+	}
+
+	cd := ecs.ContainerDefinition{
+		Name:       aws.String(name),
+		Image:      aws.String(s.Image),
+		Essential:  aws.Bool(true),
+		Command:    s.Command,
+		EntryPoint: s.Entrypoint,
+	}
+	if len(s.WorkingDir) > 0 {
+		cd.WorkingDirectory = aws.String(s.WorkingDir)
+	}
+	if len(s.User) > 0 {
+		cd.User = aws.String(s.User)
+	}
+	if len(s.Hostname) > 0 {
+		cd.Hostname = aws.String(s.Hostname)
+	}
+	if s.Privileged {
+		cd.Privileged = aws.Bool(true)
+	}
+
+	for k, v := range s.Environment {
+		if len(v) == 0 {
+			note("environment variable %s has no value", k)
+		}
+		cd.Environment = append(cd.Environment, ecs.KeyValuePair{Name: aws.String(k), Value: aws.String(v)})
+	}
+	sortEnvironment(cd.Environment)
+
+	for _, port := range s.Ports {
+		p, err := compose.ParsePort(port)
+		if err != nil {
+			return cd, notes, fmt.Errorf("service %s: %v", name, err)
+		}
+		cd.PortMappings = append(cd.PortMappings, ecs.PortMapping{
+			ContainerPort: aws.Int64(p.ContainerPort),
+			HostPort:      aws.Int64(p.HostPort),
+			Protocol:      ecs.TransportProtocol(p.Protocol),
+		})
+	}
+
+	for i, volume := range s.Volumes {
+		m, err := compose.ParseMount(volume)
+		if err != nil {
+			return cd, notes, fmt.Errorf("service %s: %v", name, err)
+		}
+
+		var v ecs.Volume
+		switch {
+		case m.IsBind() && !strings.HasPrefix(m.Source, "/"):
+			note("relative bind mount %s can not be used on container instances and is skipped", m.Source)
+			continue
+		case m.IsBind():
+			v, err = ParseVolume(volumeNameFromPath(m.Source) + ":" + m.Source)
+		case len(m.Source) == 0:
+			// anonymous volume lives as long as the task
+			v = ecs.Volume{Name: aws.String(fmt.Sprintf("%s-%d", name, i))}
+		default:
+			v, err = ParseVolume(m.Source)
+			if cv, ok := f.Volumes[m.Source]; ok && cv != nil && len(cv.Driver) > 0 {
+				v.DockerVolumeConfiguration.Driver = aws.String(cv.Driver)
+			}
+		}
+		if err != nil {
+			return cd, notes, fmt.Errorf("service %s: %v", name, err)
+		}
+
+		addTaskVolume(td, v)
+		cd.MountPoints = append(cd.MountPoints, ecs.MountPoint{
+			SourceVolume:  v.Name,
+			ContainerPath: aws.String(m.Target),
+			ReadOnly:      aws.Bool(m.ReadOnly),
+		})
+	}
+
+	for _, t := range s.Tmpfs {
+		tokens := strings.SplitN(t, ":", 2)
+		tmpfs := tokens[0]
+		if len(tokens) == 2 {
+			for _, opt := range strings.Split(tokens[1], ",") {
+				if strings.HasPrefix(opt, "size=") {
+					mib, err := compose.ParseMemory(strings.TrimPrefix(opt, "size="))
+					if err != nil {
+						return cd, notes, fmt.Errorf("service %s: %v", name, err)
+					}
+					tmpfs = fmt.Sprintf("%s:%d", tmpfs, mib)
+				}
+			}
+		}
+		tp, err := ParseTmpfs(tmpfs)
+		if err != nil {
+			return cd, notes, fmt.Errorf("service %s: %v", name, err)
+		}
+		if cd.LinuxParameters == nil {
+			cd.LinuxParameters = &ecs.LinuxParameters{}
+		}
+		cd.LinuxParameters.Tmpfs = append(cd.LinuxParameters.Tmpfs, tp)
+	}
+
+	if s.Healthcheck != nil && !s.Healthcheck.Disable && len(s.Healthcheck.Test) > 0 && s.Healthcheck.Test[0] != "NONE" {
+		hc, err := composeHealthCheck(s.Healthcheck)
+		if err != nil {
+			return cd, notes, fmt.Errorf("service %s: %v", name, err)
+		}
+		cd.HealthCheck = hc
+	}
+
+	// links and depends_on only work between containers of the same task
+	for _, link := range s.Links {
+		if !inTask[strings.SplitN(link, ":", 2)[0]] {
+			note("link to %s is dropped. use --family to put services in one task definition", link)
+			continue
+		}
+		cd.Links = append(cd.Links, link)
+	}
+	for _, dep := range s.DependsOn {
+		if !inTask[dep] {
+			note("depends_on %s is dropped. use --family to put services in one task definition", dep)
+			continue
+		}
+		cd.DependsOn = append(cd.DependsOn, ecs.ContainerDependency{ContainerName: aws.String(dep), Condition: ecs.ContainerConditionStart})
+	}
+
+	if err := composeResources(s, &cd); err != nil {
+		return cd, notes, fmt.Errorf("service %s: %v", name, err)
+	}
+	if cd.Memory == nil && cd.MemoryReservation == nil {
+		note("no memory limit or reservation. cpu %d and memory %d of --size are used", size.CPU, size.Memory)
+		size.apply(&cd)
+	}
+
+	return cd, notes, nil
+}
+
+func composeHealthCheck(h *compose.Healthcheck) (*ecs.HealthCheck, error) {
+	command := []string(h.Test)
+	// a single string is run with shell
+	if len(command) == 1 {
+		command = []string{"CMD-SHELL", command[0]}
+	}
+
+	hc := &ecs.HealthCheck{Command: command}
+	if h.Retries > 0 {
+		hc.Retries = aws.Int64(h.Retries)
+	}
+
+	durations := []struct {
+		value  string
+		target **int64
+	}{
+		{h.Interval, &hc.Interval},
+		{h.Timeout, &hc.Timeout},
+		{h.StartPeriod, &hc.StartPeriod},
+	}
+	for _, d := range durations {
+		if len(d.value) == 0 {
+			continue
+		}
+		seconds, err := compose.ParseSeconds(d.value)
+		if err != nil {
+			return nil, err
+		}
+		*d.target = aws.Int64(seconds)
+	}
+
+	return hc, nil
+}
+
+// composeResources converts mem_limit, mem_reservation, cpu_shares and deploy resources
+func composeResources(s compose.Service, cd *ecs.ContainerDefinition) error {
+	limit, reservation := s.MemLimit, s.MemReservation
+	cpus := ""
+	if s.Deploy != nil {
+		if len(s.Deploy.Resources.Limits.Memory) > 0 {
+			limit = s.Deploy.Resources.Limits.Memory
+		}
+		if len(s.Deploy.Resources.Reservations.Memory) > 0 {
+			reservation = s.Deploy.Resources.Reservations.Memory
+		}
+		cpus = s.Deploy.Resources.Limits.CPUs
+	}
+
+	if len(limit) > 0 {
+		mib, err := compose.ParseMemory(limit)
+		if err != nil {
+			return err
+		}
+		cd.Memory = aws.Int64(mib)
+	}
+	if len(reservation) > 0 {
+		mib, err := compose.ParseMemory(reservation)
+		if err != nil {
+			return err
+		}
+		cd.MemoryReservation = aws.Int64(mib)
+	}
+
+	if s.CPUShares > 0 {
+		cd.Cpu = aws.Int64(s.CPUShares)
+	}
+	if len(cpus) > 0 {
+		units, err := compose.ParseCPUs(cpus)
+		if err != nil {
+			return err
+		}
+		cd.Cpu = aws.Int64(units)
+	}
+
+	return nil
+}
+
+// volumeNameFromPath derives volume name from host path. e.g. /var/lib/data becomes var-lib-data
+func volumeNameFromPath(path string) string {
+	name := strings.Trim(nonVolumeNameRegexp.ReplaceAllString(path, "-"), "-")
+	if len(name) == 0 {
+		name = "root"
+	}
+	return name
+}
+
+// addTaskVolume adds volume unless a volume with the same name is already there
+func addTaskVolume(td *ecs.TaskDefinition, v ecs.Volume) {
+	for _, existing := range td.Volumes {
+		if aws.StringValue(existing.Name) == aws.StringValue(v.Name) {
+			return
+		}
+	}
+	td.Volumes = append(td.Volumes, v)
+	sort.SliceStable(td.Volumes, func(i, j int) bool {
+		return aws.StringValue(td.Volumes[i].Name) < aws.StringValue(td.Volumes[j].Name)
+	})
+}
@@ -0,0 +1,182 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/7onetella/morgan/internal/imageref"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+var ecsTaskdefLintCmdStrict bool
+
+var ecsTaskdefLintCmd = &cobra.Command{
+	Use:   "lint <family[:revision]|file>",
+	Short: "Lints task definitions",
+	Long: `Checks task definitions for common mistakes.
+
+* memory-reservation : memory reservation above hard memory limit      (error)
+* plaintext-secret   : secrets in environment instead of secrets       (error)
+* privileged         : privileged containers                           (error)
+* health-check       : essential containers without health check       (warning)
+* latest-tag         : images tagged latest or not tagged at all       (warning)
+* log-configuration  : containers without log configuration            (warning)
+* port-mappings      : essential containers without port mappings      (warning)
+
+Exit status is 0 when there are no errors, 1 when there are errors or, with --strict, warnings
+and 2 when a task definition could not be read.`,
+	Example: "foo-svc:12 taskdef.json --strict",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		findings := []lintFinding{}
+		for _, arg := range args {
+			td, err := LoadTaskDefinition(arg)
+			if err != nil {
+				Failure("reading task definition " + arg)
+				Println(indentation + err.Error())
+				os.Exit(2)
+			}
+
+			for _, f := range LintTaskDefinition(td) {
+				f.Source = arg
+				findings = append(findings, f)
+			}
+		}
+
+		errorCount, warningCount := 0, 0
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Task Definition", "Container", "Severity", "Rule", "Message"})
+		for _, f := range findings {
+			severity := f.Severity
+			if f.Severity == severityError {
+				errorCount++
+				severity = red(severity)
+			} else {
+				warningCount++
+			}
+			table.Append([]string{f.Source, f.Container, severity, f.Rule, f.Message})
+		}
+
+		if len(findings) > 0 {
+			table.Render()
+		}
+
+		summary := fmt.Sprintf("%d errors, %d warnings", errorCount, warningCount)
+		if errorCount > 0 || (ecsTaskdefLintCmdStrict && warningCount > 0) {
+			Failure(summary)
+			os.Exit(1)
+		}
+
+		Success(summary)
+
+	},
+}
+
+func init() {
+
+	ecsTaskdefCmd.AddCommand(ecsTaskdefLintCmd)
+
+	flags := ecsTaskdefLintCmd.Flags()
+
+	flags.BoolVar(&ecsTaskdefLintCmdStrict, "strict", false, "optional: exits with non-zero status on warnings as well")
+
+}
+
+// lintFinding is a problem found in container definition
+type lintFinding struct {
+	Source    string
+	Container string
+	Severity  string
+	Rule      string
+	Message   string
+}
+
+// LintTaskDefinition checks container definitions of task definition for common mistakes
+func LintTaskDefinition(td *ecs.TaskDefinition) []lintFinding {
+	findings := []lintFinding{}
+
+	for _, cd := range td.ContainerDefinitions {
+		name := ""
+		if cd.Name != nil {
+			name = *cd.Name
+		}
+		add := func(severity, rule, message string) {
+			findings = append(findings, lintFinding{Container: name, Severity: severity, Rule: rule, Message: message})
+		}
+
+		// containers are essential unless explicitly marked otherwise
+		essential := cd.Essential == nil || *cd.Essential
+
+		if cd.Memory != nil && cd.MemoryReservation != nil && *cd.MemoryReservation > *cd.Memory {
+			add(severityError, "memory-reservation", fmt.Sprintf("memory reservation %d is above memory limit %d", *cd.MemoryReservation, *cd.Memory))
+		}
+
+		for _, env := range cd.Environment {
+			if env.Name == nil {
+				continue
+			}
+			value := ""
+			if env.Value != nil {
+				value = *env.Value
+			}
+			if LooksLikeSecret(*env.Name, value) {
+				add(severityError, "plaintext-secret", *env.Name+" looks like a secret. use secrets with ssm parameter store or secrets manager")
+			}
+		}
+
+		if cd.Privileged != nil && *cd.Privileged {
+			add(severityError, "privileged", "container runs privileged")
+		}
+
+		if essential && (cd.HealthCheck == nil || len(cd.HealthCheck.Command) == 0) {
+			add(severityWarning, "health-check", "essential container has no health check")
+		}
+
+		if cd.Image != nil {
+			ref, err := imageref.Parse(*cd.Image)
+			if err != nil {
+				add(severityError, "image", err.Error())
+			} else if len(ref.Digest) == 0 && ref.TagOrDefault() == "latest" {
+				add(severityWarning, "latest-tag", *cd.Image+" is not pinned to a version")
+			}
+		}
+
+		if cd.LogConfiguration == nil {
+			add(severityWarning, "log-configuration", "container has no log configuration")
+		}
+
+		if essential && len(cd.PortMappings) == 0 {
+			add(severityWarning, "port-mappings", "essential container has no port mappings")
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,206 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	"github.com/spf13/cobra"
+)
+
+var ecsTaskdefCmd = &cobra.Command{
+	Use:   "taskdef",
+	Short: "Works with task definitions",
+	Long: `Works with task definitions.
+
+Task definitions are referred to by family[:revision] or by path to json file in the format
+register-task-definition --cli-input-json accepts.`,
+	Aliases: []string{"task-definition"},
+}
+
+func init() {
+
+	ecsCmd.AddCommand(ecsTaskdefCmd)
+
+}
+
+// LoadTaskDefinition reads task definition from file if arg is a path to existing file, otherwise describes family[:revision]
+func LoadTaskDefinition(arg string) (*ecs.TaskDefinition, error) {
+	if info, err := os.Stat(arg); err == nil && !info.IsDir() {
+		return ecsw.ReadTaskDefinitionFile(arg)
+	}
+
+	result, err := ecsw.DescribeTaskDefinition(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.TaskDefinition, nil
+}
+
+// LoadTaskDefinitionOrService reads task definition from file, describes family:revision or, if arg is a service, describes
+// the task definition the service runs. plain name that is not a service is described as the latest revision of the family
+func LoadTaskDefinitionOrService(arg, cluster string) (*ecs.TaskDefinition, error) {
+	if info, err := os.Stat(arg); (err == nil && !info.IsDir()) || strings.Contains(arg, ":") {
+		return LoadTaskDefinition(arg)
+	}
+
+	if len(cluster) == 0 {
+		clusters := GetClustersForService(arg)
+		CheckForClusterAmbiguity(clusters)
+		cluster = GetClusterForService(clusters, arg)
+	}
+
+	if len(cluster) == 0 {
+		return LoadTaskDefinition(arg)
+	}
+
+	_, td := DescribeServiceAndTaskDefinition(cluster, arg)
+
+	return td, nil
+}
+
+// MarshalTaskDefinition returns indented json of task definition with camel cased keys and without empty fields,
+// so that the output can be read back by LoadTaskDefinition or passed to register-task-definition --cli-input-json
+func MarshalTaskDefinition(td *ecs.TaskDefinition) ([]byte, error) {
+	data, err := json.Marshal(td)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(compactJSON(v, false), "", "  ")
+}
+
+// userMapFields are task definition fields whose keys are user defined and must be kept as is
+var userMapFields = map[string]bool{
+	"DockerLabels": true,
+	"DriverOpts":   true,
+	"Labels":       true,
+	"Options":      true,
+}
+
+// compactJSON lower cases the first letter of field names and drops null, empty and zero length values
+func compactJSON(v interface{}, userKeys bool) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, val := range t {
+			val = compactJSON(val, !userKeys && userMapFields[k])
+			if isEmptyJSON(val) {
+				continue
+			}
+			if !userKeys {
+				r := []rune(k)
+				r[0] = unicode.ToLower(r[0])
+				k = string(r)
+			}
+			m[k] = val
+		}
+		return m
+	case []interface{}:
+		l := []interface{}{}
+		for _, val := range t {
+			l = append(l, compactJSON(val, false))
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+func isEmptyJSON(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return len(t) == 0
+	case map[string]interface{}:
+		return len(t) == 0
+	case []interface{}:
+		return len(t) == 0
+	default:
+		return false
+	}
+}
+
+// taskDefinitionCache caches described task definitions. revisions are immutable so entries never go stale
+type taskDefinitionCache struct {
+	sync.Mutex
+	items map[string]*ecs.TaskDefinition
+}
+
+func newTaskDefinitionCache() *taskDefinitionCache {
+	return &taskDefinitionCache{items: map[string]*ecs.TaskDefinition{}}
+}
+
+// Get returns cached task definition or describes it. nil is returned if it can not be described
+func (c *taskDefinitionCache) Get(taskdef string) *ecs.TaskDefinition {
+	key := parseTaskDefinitionStr(taskdef)
+
+	c.Lock()
+	td, ok := c.items[key]
+	c.Unlock()
+	if ok {
+		return td
+	}
+
+	result, err := ecsw.DescribeTaskDefinition(key)
+	if err != nil {
+		Debug(err.Error())
+		return nil
+	}
+
+	c.Lock()
+	c.items[key] = result.TaskDefinition
+	c.Unlock()
+
+	return result.TaskDefinition
+}
+
+// Prefetch describes task definitions that are not cached yet, concurrency at a time
+func (c *taskDefinitionCache) Prefetch(taskdefs []string, concurrency int) {
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for _, taskdef := range taskdefs {
+		wg.Add(1)
+		go func(taskdef string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			c.Get(taskdef)
+		}(taskdef)
+	}
+
+	wg.Wait()
+}
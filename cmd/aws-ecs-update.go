@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/spf13/cobra"
 )
 
@@ -33,6 +34,10 @@ var ecsUpdateCmdTags []string
 var ecsUpdateCmdDesiredCount int64
 var ecsUpdateCmdTimeout int64
 var ecsUpdateCmdWaitForServiceStable bool
+var ecsUpdateCmdPinDigest bool
+var ecsUpdateCmdInsecureRegistry bool
+var ecsUpdateCmdSkipImageCheck bool
+var ecsUpdateCmdDeployment deploymentFlags
 
 var ecsUpdateCmd = &cobra.Command{
 	Use:   "update <service name> <docker tags>",
@@ -49,6 +54,11 @@ $ aws ecs update hello-world-v1 --desired-count 5
 if dynamic router such as fabio is used, then the web traffic will be split 50 and 50 between v1 and v2.
 
 the combination of dynamic routing and service update count can aid in safe deployment.
+deployment circuit breaker is not supported by the aws sdk version morgan is built with.
+use ecs rollback to go back to the previous task definition when a deployment fails.
+
+placement constraints and strategies are set by ecs create. update can not change them,
+the service has to be recreated to place tasks differently.
 `,
 	Example: "foo-svc 1.0.0 --cluster api-cluster",
 	Aliases: []string{"update-service"},
@@ -72,6 +82,9 @@ the combination of dynamic routing and service update count can aid in safe depl
 			}
 		}
 
+		opts, err := ecsUpdateCmdDeployment.options(cmd.Flags())
+		ExitOnError(err, "validating deployment options")
+
 		// if cluster is not specified, then assume there is only one cluster and use that cluster
 		if len(cluster) == 0 {
 			clusters := GetClustersForService(service)
@@ -81,11 +94,14 @@ the combination of dynamic routing and service update count can aid in safe depl
 			cluster = GetClusterForService(clusters, service)
 		}
 
+		d := BeginDeployment("update", cluster, service)
+
 		result, err := ecsw.DescribeServices(cluster, service)
 		ExitOnError(err, "describing services")
 		if len(result.Services) == 0 {
 			ExitOnError(errors.New("search result count 0"), "finding service")
 		}
+		d.Before(result.Services[0])
 		taskdef = *result.Services[0].TaskDefinition
 
 		// if --desired-count is not specified use the current count from service
@@ -97,27 +113,45 @@ the combination of dynamic routing and service update count can aid in safe depl
 		ExitOnError(err, "describing task definition")
 
 		// if tags are specified only then update the tags in container definition
-		if len(tags) > 0 {
-			for i, cd := range result2.TaskDefinition.ContainerDefinitions {
-				imageCurr := *cd.Image
-				colonIndex := strings.LastIndex(imageCurr, ":")
-				imageBase := imageCurr[:colonIndex]
+		// tags are applied to container definitions in order
+		cds := result2.TaskDefinition.ContainerDefinitions
+		for i := range cds {
+			ref := ParseImage(*cds[i].Image)
+			if i < len(tags) {
 				// update the image with new docker tag
-				imageCurr = imageBase + tags[i]
+				ref = ref.WithTag(tags[i])
 			}
+			image := ref.String()
+			if !ecsUpdateCmdSkipImageCheck {
+				err = VerifyImage(image)
+				ExitOn(err)
+			}
+			if ecsUpdateCmdPinDigest {
+				image = PinImageDigest(image, ecsUpdateCmdInsecureRegistry)
+			}
+			cds[i].Image = aws.String(image)
 		}
 
 		result3, err := ecsw.RegisterTaskDefinition(result2.TaskDefinition)
 		ExitOnError(err, "registering task definition")
 
-		_, err = ecsw.UpdateService(cluster, service, *result3.TaskDefinition.TaskDefinitionArn, ecsUpdateCmdDesiredCount)
+		d.After(*result3.TaskDefinition.TaskDefinitionArn, ecsUpdateCmdDesiredCount)
+		d.Images = taskDefinitionImages(result3.TaskDefinition)
+
+		err = d.Pre()
+		ExitOn(err)
+
+		_, err = ecsw.UpdateServiceWithOptions(cluster, service, *result3.TaskDefinition.TaskDefinitionArn, ecsUpdateCmdDesiredCount, opts)
 		ExitOnError(err, "updating service")
+		d.Changed()
 
 		if ecsUpdateCmdWaitForServiceStable {
 			err = ecsw.ServiceStable(cluster, service, ecsUpdateCmdTimeout)
 			ExitOnError(err, "service stable")
 		}
 
+		d.Finish(nil)
+
 		Success("updating service")
 
 	},
@@ -139,4 +173,12 @@ func init() {
 
 	flags.StringSliceVarP(&ecsUpdateCmdTags, "docker-tags", "t", []string{}, `optional: docker tags. ex) -docker-tags="1.0.0,2.0.0"`)
 
+	flags.BoolVar(&ecsUpdateCmdPinDigest, "pin-digest", false, "optional: resolves docker tags to immutable digests before registering task definition")
+
+	flags.BoolVar(&ecsUpdateCmdInsecureRegistry, "insecure-registry", false, "optional: uses http to talk to docker registry when resolving digest")
+
+	flags.BoolVar(&ecsUpdateCmdSkipImageCheck, "skip-image-check", false, "optional: skips checking that ecr images exist")
+
+	addDeploymentFlags(flags, &ecsUpdateCmdDeployment, true)
+
 }
@@ -21,9 +21,11 @@
 package cmd
 
 import (
+	"errors"
 	"os"
 
 	"github.com/7onetella/morgan/tools/awsapi/ecsw"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
 	"github.com/spf13/cobra"
 )
 
@@ -71,3 +73,31 @@ func GetClustersForService(service string) map[string]string {
 
 	return clusters
 }
+
+// ResolveCluster returns the specified cluster or the only cluster the given service runs in
+func ResolveCluster(cluster, service string) string {
+	if len(cluster) > 0 {
+		return cluster
+	}
+
+	clusters := GetClustersForService(service)
+
+	CheckForClusterAmbiguity(clusters)
+
+	return GetClusterForService(clusters, service)
+}
+
+// DescribeServiceAndTaskDefinition describes service and the task definition it currently runs
+func DescribeServiceAndTaskDefinition(cluster, service string) (ecs.Service, *ecs.TaskDefinition) {
+	result, err := ecsw.DescribeServices(cluster, service)
+	ExitOnError(err, "describing services")
+	if len(result.Services) == 0 {
+		ExitOnError(errors.New("search result count 0"), "finding service")
+	}
+	s := result.Services[0]
+
+	result2, err := ecsw.DescribeTaskDefinition(*s.TaskDefinition)
+	ExitOnError(err, "describing task definition")
+
+	return s, result2.TaskDefinition
+}
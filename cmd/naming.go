@@ -0,0 +1,100 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package cmd
+
+import (
+	"errors"
+
+	"github.com/7onetella/morgan/internal/naming"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// namingFlags are parts names are generated from
+type namingFlags struct {
+	team        string
+	app         string
+	environment string
+}
+
+// addNamingFlags adds --team, --app and --environment. --env is already taken by environment variables of ecs create
+func addNamingFlags(flags *pflag.FlagSet, n *namingFlags) {
+
+	flags.StringVar(&n.team, "team", "", "optional: team part of generated name")
+
+	flags.StringVar(&n.app, "app", "", "optional: app part of generated name")
+
+	flags.StringVar(&n.environment, "environment", "", "optional: env part of generated name")
+
+}
+
+func (n namingFlags) specified() bool {
+	return len(n.team) > 0 || len(n.app) > 0 || len(n.environment) > 0
+}
+
+func (n namingFlags) parts() map[string]string {
+	return map[string]string{
+		"team": n.team,
+		"app":  n.app,
+		"env":  n.environment,
+	}
+}
+
+// NamingPolicy returns naming policy configured under naming in ~/.morgan.yaml. e.g.
+//
+//	naming:
+//	  service:
+//	    pattern: "{team}-{app}-{env}"
+//	    maxLength: 32
+//	    allowed: a-z0-9-
+//	    values:
+//	      env: [dev, qa, prod]
+//	  dns:
+//	    pattern: "{app}.{env}.example.com"
+func NamingPolicy() naming.Policy {
+	policy := naming.Policy{}
+
+	err := viper.UnmarshalKey("naming", &policy)
+	ExitOnError(err, "reading naming policy")
+
+	return policy
+}
+
+// ResolveName generates name of kind from --team, --app and --environment or validates the given name
+func ResolveName(kind, name string, n namingFlags) string {
+	policy := NamingPolicy()
+
+	if !n.specified() {
+		ExitOn(policy.Validate(kind, name))
+		return name
+	}
+
+	if len(name) > 0 {
+		ExitOn(errors.New("specify either " + kind + " name or --team, --app and --environment"))
+	}
+
+	name, err := policy.Generate(kind, n.parts())
+	ExitOn(err)
+
+	Info("generated " + kind + " name " + name)
+
+	return name
+}
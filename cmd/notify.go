@@ -0,0 +1,105 @@
+// Copyright © 2019 Seven OneTella<7onetella@gmail.com>
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+package cmd
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/notify"
+	"github.com/spf13/viper"
+)
+
+// NotifyConfig reads notification webhooks from ~/.morgan.yaml. actions limits a webhook to those actions.
+//
+// e.g.
+//
+//	notifications:
+//	  webhooks:
+//	    - type: slack
+//	      url: https://hooks.slack.com/services/T000/B000/XXXX
+//	    - type: mattermost
+//	      url: https://chat.example.com/hooks/xxxx
+//	      actions: [update, rollback]
+//	    - type: http
+//	      url: https://deploys.example.com/events
+//	      timeout: 2
+func NotifyConfig() (notify.Config, error) {
+	config := notify.Config{}
+	err := viper.UnmarshalKey("notifications", &config)
+
+	return config, err
+}
+
+// NotificationsEnabled checks to see if any webhook wants to be notified of action
+func NotificationsEnabled(action string) bool {
+	config, err := NotifyConfig()
+	return err == nil && config.Enabled(action)
+}
+
+// Notify posts event to webhooks. delivery is best-effort. failures are reported and never fail the command
+func Notify(e notify.Event) {
+	config, err := NotifyConfig()
+	if err != nil {
+		Failure("reading notifications config")
+		Debug(err.Error())
+		return
+	}
+
+	if len(e.User) == 0 {
+		e.User = currentUser()
+	}
+
+	for _, err := range notify.Send(config, e) {
+		Failure("sending notification")
+		Debug(err.Error())
+	}
+}
+
+// NotifyInstances posts outcome of ec2 action on instances. instanceIDs maps instance id to name
+func NotifyInstances(action string, instanceIDs map[string]string, ids []string, started time.Time, err error) {
+	if !NotificationsEnabled(action) {
+		return
+	}
+
+	names := []string{}
+	for _, id := range ids {
+		name := instanceIDs[id]
+		if len(name) == 0 {
+			name = id
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	e := notify.Event{
+		Kind:      "ec2",
+		Action:    action,
+		Target:    strings.Join(names, ","),
+		Duration:  time.Since(started),
+		Succeeded: err == nil,
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+
+	Notify(e)
+}
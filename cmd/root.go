@@ -134,6 +134,17 @@ var bullet = "\u2022 "
 var _isTerminal bool
 var loggingLevel = 0
 
+// exitHooks are run before morgan exits because of an error
+var exitHooks []func(err error)
+
+func runExitHooks(err error) {
+	hooks := exitHooks
+	exitHooks = nil
+	for _, hook := range hooks {
+		hook(err)
+	}
+}
+
 // CheckArgs checks to see if minimum number of args are provided
 func CheckArgs(args []string, minimum int) {
 	if len(args) < minimum {
@@ -150,6 +161,7 @@ func ExitOnError(err error, action string) {
 			Print("\n")
 			IndentRed(err.Error())
 		}
+		runExitHooks(err)
 		os.Exit(1)
 	}
 
@@ -169,6 +181,7 @@ func ExitOn(err error) {
 		Print("\n")
 		// IndentRed(err.Error())
 		// }
+		runExitHooks(err)
 		os.Exit(1)
 	}
 }
@@ -182,6 +195,7 @@ func ExitOnErrorWithDetail(err error, action, stdout, errout string) {
 			IndentRed(stdout)
 			IndentRed(errout)
 		}
+		runExitHooks(err)
 		os.Exit(1)
 	}
 
@@ -363,7 +377,8 @@ func ConvertKeyValuePairArgSliceToMap(keyvals []string) map[string]string {
 	data := map[string]string{}
 	for _, keyval := range keyvals {
 		s := strings.TrimSpace(keyval)
-		tokens := strings.Split(s, "=")
+		// values may contain = e.g. base64 padding
+		tokens := strings.SplitN(s, "=", 2)
 		// avoid error
 		if len(tokens) != 2 {
 			continue
@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/7onetella/morgan/internal/cryptow/ssha"
+	"github.com/7onetella/morgan/internal/naming"
 	"github.com/spf13/cobra"
 )
 
@@ -57,6 +58,7 @@ var userCreateCmd = &cobra.Command{
 		ldap := NewLDAP(cfg)
 
 		uid := strings.ToLower(userCreateCmdLast) + strings.ToLower(userCreateCmdFirst)[0:1]
+		ExitOn(NamingPolicy().Validate(naming.KindUser, uid))
 		passwordHash, err := ssha.Encode([]byte(cfg.Password))
 		ExitOnError(err, "hashing password")
 
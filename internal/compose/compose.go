@@ -0,0 +1,378 @@
+package compose
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// File is the subset of docker-compose file morgan converts to and from task definitions
+type File struct {
+	Version  string             `yaml:"version,omitempty"`
+	Services map[string]Service `yaml:"services"`
+	Volumes  map[string]*Volume `yaml:"volumes,omitempty"`
+	// Extra keeps top level keys that are not supported
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Service is a compose service
+type Service struct {
+	Image          string       `yaml:"image,omitempty"`
+	Command        StringOrList `yaml:"command,omitempty"`
+	Entrypoint     StringOrList `yaml:"entrypoint,omitempty"`
+	Environment    Environment  `yaml:"environment,omitempty"`
+	Ports          []string     `yaml:"ports,omitempty"`
+	Volumes        []string     `yaml:"volumes,omitempty"`
+	Tmpfs          StringOrList `yaml:"tmpfs,omitempty"`
+	Links          []string     `yaml:"links,omitempty"`
+	DependsOn      []string     `yaml:"depends_on,omitempty"`
+	Healthcheck    *Healthcheck `yaml:"healthcheck,omitempty"`
+	WorkingDir     string       `yaml:"working_dir,omitempty"`
+	User           string       `yaml:"user,omitempty"`
+	Hostname       string       `yaml:"hostname,omitempty"`
+	Privileged     bool         `yaml:"privileged,omitempty"`
+	MemLimit       string       `yaml:"mem_limit,omitempty"`
+	MemReservation string       `yaml:"mem_reservation,omitempty"`
+	CPUShares      int64        `yaml:"cpu_shares,omitempty"`
+	Deploy         *Deploy      `yaml:"deploy,omitempty"`
+	// Extra keeps keys that are not supported
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Healthcheck is a compose healthcheck
+type Healthcheck struct {
+	Test        StringOrList `yaml:"test,omitempty"`
+	Interval    string       `yaml:"interval,omitempty"`
+	Timeout     string       `yaml:"timeout,omitempty"`
+	Retries     int64        `yaml:"retries,omitempty"`
+	StartPeriod string       `yaml:"start_period,omitempty"`
+	Disable     bool         `yaml:"disable,omitempty"`
+}
+
+// Deploy is the resources part of compose deploy section
+type Deploy struct {
+	Resources struct {
+		Limits       Resources `yaml:"limits,omitempty"`
+		Reservations Resources `yaml:"reservations,omitempty"`
+	} `yaml:"resources,omitempty"`
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// Resources are cpus and memory of deploy section
+type Resources struct {
+	CPUs   string `yaml:"cpus,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// Volume is a top level named volume
+type Volume struct {
+	Driver     string            `yaml:"driver,omitempty"`
+	DriverOpts map[string]string `yaml:"driver_opts,omitempty"`
+	External   bool              `yaml:"external,omitempty"`
+}
+
+// StringOrList is either a string or a list of strings. e.g. command
+type StringOrList []string
+
+// UnmarshalYAML accepts both a string and a list
+func (s *StringOrList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*s = StringOrList{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*s = list
+
+	return nil
+}
+
+// Environment is either a map or a list of KEY=value
+type Environment map[string]string
+
+// UnmarshalYAML accepts both a map and a list
+func (e *Environment) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	m := map[string]interface{}{}
+	if err := unmarshal(&m); err == nil {
+		env := Environment{}
+		for k, v := range m {
+			if v == nil {
+				env[k] = ""
+				continue
+			}
+			env[k] = fmt.Sprintf("%v", v)
+		}
+		*e = env
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+
+	env := Environment{}
+	for _, kv := range list {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) == 1 {
+			env[pair[0]] = ""
+			continue
+		}
+		env[pair[0]] = pair[1]
+	}
+	*e = env
+
+	return nil
+}
+
+// Parse parses docker-compose file
+func Parse(data []byte) (*File, error) {
+	f := &File{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+
+	if len(f.Services) == 0 {
+		return nil, fmt.Errorf("no services found")
+	}
+
+	return f, nil
+}
+
+// Marshal marshals file to yaml
+func (f *File) Marshal() ([]byte, error) {
+	return yaml.Marshal(f)
+}
+
+// ServiceNames returns names of services sorted
+func (f *File) ServiceNames() []string {
+	names := []string{}
+	for name := range f.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Unsupported lists keys that are not converted
+func (f *File) Unsupported() []string {
+	unsupported := []string{}
+
+	for _, key := range sortedKeys(f.Extra) {
+		unsupported = append(unsupported, key+" is not supported")
+	}
+
+	for _, name := range f.ServiceNames() {
+		s := f.Services[name]
+		for _, key := range sortedKeys(s.Extra) {
+			unsupported = append(unsupported, "service "+name+": "+key+" is not supported")
+		}
+		if s.Deploy != nil {
+			for _, key := range sortedKeys(s.Deploy.Extra) {
+				unsupported = append(unsupported, "service "+name+": deploy."+key+" is not supported")
+			}
+		}
+	}
+
+	return unsupported
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Port is a parsed port mapping
+type Port struct {
+	HostPort      int64
+	ContainerPort int64
+	Protocol      string
+}
+
+// ParsePort parses short port syntax. e.g. 80, 8080:80, 127.0.0.1:8080:80/udp
+func ParsePort(s string) (Port, error) {
+	p := Port{Protocol: "tcp"}
+
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		p.Protocol = s[i+1:]
+		s = s[:i]
+	}
+	if p.Protocol != "tcp" && p.Protocol != "udp" {
+		return p, fmt.Errorf("unsupported protocol %s", p.Protocol)
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return p, fmt.Errorf("invalid port %s", s)
+	}
+	if strings.Contains(parts[len(parts)-1], "-") {
+		return p, fmt.Errorf("port ranges are not supported: %s", s)
+	}
+
+	containerPort, err := strconv.ParseInt(parts[len(parts)-1], 10, 64)
+	if err != nil {
+		return p, fmt.Errorf("invalid container port %s", s)
+	}
+	p.ContainerPort = containerPort
+
+	if len(parts) > 1 && len(parts[len(parts)-2]) > 0 {
+		hostPort, err := strconv.ParseInt(parts[len(parts)-2], 10, 64)
+		if err != nil {
+			return p, fmt.Errorf("invalid host port %s", s)
+		}
+		p.HostPort = hostPort
+	}
+
+	return p, nil
+}
+
+// String formats port in short syntax
+func (p Port) String() string {
+	s := strconv.FormatInt(p.ContainerPort, 10)
+	if p.HostPort > 0 {
+		s = strconv.FormatInt(p.HostPort, 10) + ":" + s
+	}
+	if len(p.Protocol) > 0 && p.Protocol != "tcp" {
+		s += "/" + p.Protocol
+	}
+	return s
+}
+
+// Mount is a parsed service volume
+type Mount struct {
+	// Source is host path or named volume. empty for anonymous volume
+	Source   string
+	Target   string
+	ReadOnly bool
+}
+
+// IsBind checks to see if source is host path
+func (m Mount) IsBind() bool {
+	return strings.HasPrefix(m.Source, "/") || strings.HasPrefix(m.Source, ".") || strings.HasPrefix(m.Source, "~")
+}
+
+// ParseMount parses short volume syntax. e.g. /data, data:/data, ./conf:/etc/app:ro
+func ParseMount(s string) (Mount, error) {
+	parts := strings.Split(s, ":")
+
+	m := Mount{}
+	switch len(parts) {
+	case 1:
+		m.Target = parts[0]
+	case 2:
+		m.Source, m.Target = parts[0], parts[1]
+	case 3:
+		m.Source, m.Target = parts[0], parts[1]
+		for _, opt := range strings.Split(parts[2], ",") {
+			if opt == "ro" {
+				m.ReadOnly = true
+			}
+		}
+	default:
+		return m, fmt.Errorf("invalid volume %s", s)
+	}
+
+	if !strings.HasPrefix(m.Target, "/") {
+		return m, fmt.Errorf("volume target must be absolute path: %s", s)
+	}
+
+	return m, nil
+}
+
+// String formats mount in short syntax
+func (m Mount) String() string {
+	s := m.Target
+	if len(m.Source) > 0 {
+		s = m.Source + ":" + s
+	}
+	if m.ReadOnly {
+		s += ":ro"
+	}
+	return s
+}
+
+// ParseMemory parses memory such as 512m, 1g or bytes and returns MiB
+func ParseMemory(s string) (int64, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSuffix(s, "b")
+
+	multiplier := 1.0 / (1024 * 1024)
+	switch {
+	case strings.HasSuffix(s, "k"):
+		multiplier = 1.0 / 1024
+	case strings.HasSuffix(s, "m"):
+		multiplier = 1
+	case strings.HasSuffix(s, "g"):
+		multiplier = 1024
+	}
+	s = strings.TrimRight(s, "kmg")
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid memory %s", s)
+	}
+
+	return int64(v * multiplier), nil
+}
+
+// FormatMemory formats MiB
+func FormatMemory(mib int64) string {
+	return strconv.FormatInt(mib, 10) + "m"
+}
+
+// ParseCPUs converts cpus such as 0.5 to cpu units where 1024 units is one cpu
+func ParseCPUs(s string) (int64, error) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cpus %s", s)
+	}
+	return int64(v * 1024), nil
+}
+
+// ParseSeconds parses compose duration such as 30s or 1m30s into seconds
+func ParseSeconds(s string) (int64, error) {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	return int64(d.Seconds()), nil
+}
+
+// FormatSeconds formats seconds as compose duration
+func FormatSeconds(seconds int64) string {
+	return (time.Duration(seconds) * time.Second).String()
+}
@@ -0,0 +1,156 @@
+package compose
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"strings"
+	"testing"
+)
+
+const testCompose = `
+version: "3.7"
+services:
+  web:
+    image: nginx:1.17
+    ports:
+      - "8080:80"
+      - 443
+    environment:
+      - NAME=web
+      - DEBUG
+    volumes:
+      - ./conf:/etc/nginx/conf.d:ro
+      - cache:/var/cache/nginx
+    links:
+      - api
+    build: .
+  api:
+    image: app/api:1.0.0
+    command: ["serve", "--port", "8080"]
+    environment:
+      URL: http://example.com/?a=b
+      RETRIES: 3
+    healthcheck:
+      test: ["CMD", "curl", "-f", "http://localhost:8080/health"]
+      interval: 30s
+      retries: 3
+    deploy:
+      resources:
+        limits:
+          cpus: "0.5"
+          memory: 512M
+      replicas: 2
+volumes:
+  cache:
+networks:
+  default:
+`
+
+func TestParse(t *testing.T) {
+
+	f, err := Parse([]byte(testCompose))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if names := strings.Join(f.ServiceNames(), ","); names != "api,web" {
+		t.Errorf("ServiceNames() = %s", names)
+	}
+
+	web := f.Services["web"]
+	if web.Environment["NAME"] != "web" || web.Environment["DEBUG"] != "" {
+		t.Errorf("environment = %v", web.Environment)
+	}
+
+	api := f.Services["api"]
+	if len(api.Command) != 3 || api.Environment["RETRIES"] != "3" {
+		t.Errorf("api = %+v", api)
+	}
+	if api.Deploy.Resources.Limits.Memory != "512M" {
+		t.Errorf("deploy = %+v", api.Deploy)
+	}
+
+	unsupported := strings.Join(f.Unsupported(), "\n")
+	for _, expected := range []string{"networks is not supported", "service web: build is not supported", "service api: deploy.replicas is not supported"} {
+		if !strings.Contains(unsupported, expected) {
+			t.Errorf("Unsupported() = %s, missing %s", unsupported, expected)
+		}
+	}
+}
+
+func TestParsePort(t *testing.T) {
+
+	tests := []struct {
+		s        string
+		expected Port
+	}{
+		{"80", Port{0, 80, "tcp"}},
+		{"8080:80", Port{8080, 80, "tcp"}},
+		{"127.0.0.1:8080:80/udp", Port{8080, 80, "udp"}},
+	}
+
+	for _, test := range tests {
+		p, err := ParsePort(test.s)
+		if err != nil || p != test.expected {
+			t.Errorf("ParsePort(%s) = %+v, %v", test.s, p, err)
+		}
+	}
+
+	if _, err := ParsePort("8000-8010:8000-8010"); err == nil {
+		t.Error("expected error for port range")
+	}
+}
+
+func TestParseMount(t *testing.T) {
+
+	m, err := ParseMount("./conf:/etc/nginx/conf.d:ro")
+	if err != nil || !m.IsBind() || !m.ReadOnly || m.Target != "/etc/nginx/conf.d" {
+		t.Errorf("ParseMount() = %+v, %v", m, err)
+	}
+
+	m, err = ParseMount("cache:/var/cache")
+	if err != nil || m.IsBind() || m.Source != "cache" {
+		t.Errorf("ParseMount() = %+v, %v", m, err)
+	}
+
+	if m.String() != "cache:/var/cache" {
+		t.Errorf("String() = %s", m.String())
+	}
+}
+
+func TestParseMemory(t *testing.T) {
+
+	tests := map[string]int64{
+		"512M":      512,
+		"1g":        1024,
+		"268435456": 256,
+		"1.5GB":     1536,
+	}
+
+	for s, expected := range tests {
+		mib, err := ParseMemory(s)
+		if err != nil || mib != expected {
+			t.Errorf("ParseMemory(%s) = %d, %v", s, mib, err)
+		}
+	}
+}
@@ -0,0 +1,357 @@
+package cron
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule is parsed EventBridge cron expression: minutes hours day-of-month month day-of-week year.
+// times are in UTC as EventBridge evaluates them
+type Schedule struct {
+	expression string
+	minutes    []bool
+	hours      []bool
+	months     []bool
+	years      []bool
+	days       dayMatcher
+	weekdays   dayMatcher
+	// exactly one of days and weekdays is ? in EventBridge
+	anyDay     bool
+	anyWeekday bool
+}
+
+type dayMatcher struct {
+	values []bool
+	// last day of month or last given weekday of month
+	last bool
+	// nearest weekday to day of month
+	nearestWeekday int
+	// nth given weekday of month. e.g. 2 for 6#2
+	nth int
+}
+
+const (
+	minYear = 1970
+	maxYear = 2199
+)
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// day of week is 1 for sunday through 7 for saturday
+var weekdayNames = map[string]int{
+	"SUN": 1, "MON": 2, "TUE": 3, "WED": 4, "THU": 5, "FRI": 6, "SAT": 7,
+}
+
+// Parse parses cron expression with or without cron( ) around it. e.g. 0 3 * * ? *
+func Parse(expression string) (*Schedule, error) {
+	expr := strings.TrimSpace(expression)
+	if strings.HasPrefix(expr, "cron(") && strings.HasSuffix(expr, ")") {
+		expr = strings.TrimSpace(expr[len("cron(") : len(expr)-1])
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 6 {
+		return nil, fmt.Errorf("cron expression must have 6 fields: minutes hours day-of-month month day-of-week year. got %d", len(fields))
+	}
+
+	s := &Schedule{expression: strings.Join(fields, " ")}
+	var err error
+
+	if s.minutes, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("minutes: %v", err)
+	}
+	if s.hours, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("hours: %v", err)
+	}
+	if s.months, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("month: %v", err)
+	}
+	if s.years, err = parseField(fields[5], minYear, maxYear, nil); err != nil {
+		return nil, fmt.Errorf("year: %v", err)
+	}
+
+	s.anyDay = fields[2] == "?"
+	s.anyWeekday = fields[4] == "?"
+	if s.anyDay == s.anyWeekday {
+		return nil, errors.New("one of day-of-month and day-of-week must be ? and the other must not")
+	}
+
+	if !s.anyDay {
+		if s.days, err = parseDays(fields[2]); err != nil {
+			return nil, fmt.Errorf("day-of-month: %v", err)
+		}
+	}
+	if !s.anyWeekday {
+		if s.weekdays, err = parseWeekdays(fields[4]); err != nil {
+			return nil, fmt.Errorf("day-of-week: %v", err)
+		}
+	}
+
+	return s, nil
+}
+
+// String returns expression in the form EventBridge expects
+func (s *Schedule) String() string {
+	return "cron(" + s.expression + ")"
+}
+
+// Next returns next n run times after t
+func (s *Schedule) Next(t time.Time, n int) []time.Time {
+	times := []time.Time{}
+
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	for len(times) < n && day.Year() <= maxYear {
+		if s.matchesDay(day) {
+			for h := 0; h < 24 && len(times) < n; h++ {
+				if !s.hours[h] {
+					continue
+				}
+				for m := 0; m < 60 && len(times) < n; m++ {
+					if !s.minutes[m] {
+						continue
+					}
+					run := day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+					if !run.Before(t) {
+						times = append(times, run)
+					}
+				}
+			}
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+
+	return times
+}
+
+func (s *Schedule) matchesDay(day time.Time) bool {
+	if !s.years[day.Year()-minYear] || !s.months[int(day.Month())-1] {
+		return false
+	}
+	if !s.anyDay {
+		return s.days.matchesDayOfMonth(day)
+	}
+	return s.weekdays.matchesDayOfWeek(day)
+}
+
+func (d dayMatcher) matchesDayOfMonth(day time.Time) bool {
+	last := lastDayOfMonth(day)
+
+	switch {
+	case d.last && d.nearestWeekday < 0:
+		// LW is last weekday of month
+		return day.Day() == nearestWeekday(day, last)
+	case d.last:
+		return day.Day() == last
+	case d.nearestWeekday > 0:
+		return day.Day() == nearestWeekday(day, d.nearestWeekday)
+	default:
+		return d.values[day.Day()-1]
+	}
+}
+
+func (d dayMatcher) matchesDayOfWeek(day time.Time) bool {
+	weekday := int(day.Weekday()) + 1
+	if !d.values[weekday-1] {
+		return false
+	}
+
+	switch {
+	case d.last:
+		return day.Day()+7 > lastDayOfMonth(day)
+	case d.nth > 0:
+		return (day.Day()-1)/7+1 == d.nth
+	default:
+		return true
+	}
+}
+
+func lastDayOfMonth(day time.Time) int {
+	return time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// nearestWeekday returns weekday nearest to day of month without crossing into other month
+func nearestWeekday(day time.Time, dom int) int {
+	last := lastDayOfMonth(day)
+	if dom > last {
+		dom = last
+	}
+
+	target := time.Date(day.Year(), day.Month(), dom, 0, 0, 0, 0, time.UTC)
+	switch target.Weekday() {
+	case time.Saturday:
+		if dom == 1 {
+			return 3
+		}
+		return dom - 1
+	case time.Sunday:
+		if dom == last {
+			return dom - 2
+		}
+		return dom + 1
+	default:
+		return dom
+	}
+}
+
+func parseDays(field string) (dayMatcher, error) {
+	d := dayMatcher{}
+
+	switch {
+	case field == "L":
+		d.last = true
+		return d, nil
+	case field == "LW":
+		d.last = true
+		d.nearestWeekday = -1
+		return d, nil
+	case strings.HasSuffix(field, "W"):
+		dom, err := strconv.Atoi(strings.TrimSuffix(field, "W"))
+		if err != nil || dom < 1 || dom > 31 {
+			return d, fmt.Errorf("invalid nearest weekday %s", field)
+		}
+		d.nearestWeekday = dom
+		return d, nil
+	}
+
+	values, err := parseField(field, 1, 31, nil)
+	d.values = values
+	return d, err
+}
+
+func parseWeekdays(field string) (dayMatcher, error) {
+	d := dayMatcher{}
+
+	switch {
+	case field == "L":
+		// L alone is the last day of week which is saturday
+		field = "7"
+	case strings.HasSuffix(field, "L"):
+		d.last = true
+		field = strings.TrimSuffix(field, "L")
+	case strings.Contains(field, "#"):
+		tokens := strings.SplitN(field, "#", 2)
+		nth, err := strconv.Atoi(tokens[1])
+		if err != nil || nth < 1 || nth > 5 {
+			return d, fmt.Errorf("invalid nth day of week %s", field)
+		}
+		d.nth = nth
+		field = tokens[0]
+	}
+
+	values, err := parseField(field, 1, 7, weekdayNames)
+	if err != nil {
+		return d, err
+	}
+	if (d.last || d.nth > 0) && count(values) != 1 {
+		return d, errors.New("L and # require a single day of week")
+	}
+
+	d.values = values
+	return d, nil
+}
+
+// parseField parses comma separated values, ranges and increments into set of allowed values
+func parseField(field string, min, max int, names map[string]int) ([]bool, error) {
+	values := make([]bool, max-min+1)
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s < 1 {
+				return nil, fmt.Errorf("invalid increment %s", part)
+			}
+			step = s
+			part = part[:i]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, "-"):
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if start, err = parseValue(bounds[0], min, max, names); err != nil {
+				return nil, err
+			}
+			if end, err = parseValue(bounds[1], min, max, names); err != nil {
+				return nil, err
+			}
+			if start > end {
+				return nil, fmt.Errorf("invalid range %s", part)
+			}
+		default:
+			v, err := parseValue(part, min, max, names)
+			if err != nil {
+				return nil, err
+			}
+			start = v
+			// a/b means from a every b
+			if step == 1 {
+				end = v
+			}
+		}
+
+		for v := start; v <= end; v += step {
+			values[v-min] = true
+		}
+	}
+
+	return values, nil
+}
+
+func parseValue(s string, min, max int, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %s", s)
+	}
+	if v < min || v > max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, min, max)
+	}
+
+	return v, nil
+}
+
+func count(values []bool) int {
+	n := 0
+	for _, v := range values {
+		if v {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,89 @@
+package cron
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"testing"
+	"time"
+)
+
+var from = time.Date(2019, time.June, 14, 10, 30, 0, 0, time.UTC) // friday
+
+func TestNext(t *testing.T) {
+
+	tests := []struct {
+		expression string
+		expected   []string
+	}{
+		{"0 3 * * ? *", []string{"2019-06-15 03:00", "2019-06-16 03:00"}},
+		{"cron(0/15 10 * * ? *)", []string{"2019-06-14 10:45", "2019-06-15 10:00"}},
+		{"0 18 ? * MON-FRI *", []string{"2019-06-14 18:00", "2019-06-17 18:00"}},
+		{"0 8 1 JAN,JUL ? *", []string{"2019-07-01 08:00", "2020-01-01 08:00"}},
+		{"0 0 L * ? *", []string{"2019-06-30 00:00", "2019-07-31 00:00"}},
+		{"0 0 ? * 6L *", []string{"2019-06-28 00:00", "2019-07-26 00:00"}},
+		{"0 0 ? * 2#1 *", []string{"2019-07-01 00:00", "2019-08-05 00:00"}},
+		{"0 0 15W * ? *", []string{"2019-07-15 00:00", "2019-08-15 00:00"}},
+		{"0 0 1 1 ? 2020", []string{"2020-01-01 00:00"}},
+	}
+
+	for _, test := range tests {
+		s, err := Parse(test.expression)
+		if err != nil {
+			t.Errorf("Parse(%s) = %v", test.expression, err)
+			continue
+		}
+
+		runs := s.Next(from, 2)
+		if len(runs) != len(test.expected) {
+			t.Errorf("Next(%s) = %v", test.expression, runs)
+			continue
+		}
+		for i, run := range runs {
+			if run.Format("2006-01-02 15:04") != test.expected[i] {
+				t.Errorf("Next(%s)[%d] = %s, expected %s", test.expression, i, run.Format("2006-01-02 15:04"), test.expected[i])
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+
+	invalid := []string{
+		"0 3 * * *",
+		"0 3 * * * *",
+		"0 3 ? * ? *",
+		"60 3 * * ? *",
+		"0 24 * * ? *",
+		"0 3 * 13 ? *",
+		"0 3 ? * MON#6 *",
+		"0 3 ? * MON-FRI#2 *",
+		"0 3 32W * ? *",
+		"0 3 5-1 * ? *",
+	}
+
+	for _, expression := range invalid {
+		if _, err := Parse(expression); err == nil {
+			t.Errorf("Parse(%s) expected error", expression)
+		}
+	}
+}
@@ -24,8 +24,12 @@ package execw
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"os"
 	"os/exec"
+	"time"
 )
 
 // Execute execute
@@ -88,3 +92,33 @@ func Exec(args []string) (string, string, error) {
 
 	return stdout, errout, err
 }
+
+// Run runs command attached to stdin, stdout and stderr of morgan and waits for it to exit
+func Run(args []string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// ExecEnv runs command with env added to the environment of morgan. the command is killed after timeout
+func ExecEnv(args []string, env []string, timeout time.Duration) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+
+	var stdout, errout bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &errout
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+
+	return stdout.String(), errout.String(), err
+}
@@ -0,0 +1,240 @@
+package hooks
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/execw"
+)
+
+const (
+	// PhasePre runs before the change. failing pre hook aborts the action
+	PhasePre = "pre"
+	// PhasePost runs after the action changed the service, whether it succeeded or not.
+	// it does not run when pre hooks or checks failed before the service was changed
+	PhasePost = "post"
+)
+
+// DefaultTimeout applies to hooks without timeout
+const DefaultTimeout = 60
+
+// Hook is a shell command, http webhook or one-off ecs task. exactly one of Command, URL and Task is set
+type Hook struct {
+	Name    string `mapstructure:"name"`
+	Command string `mapstructure:"command"`
+	URL     string `mapstructure:"url"`
+	Task    string `mapstructure:"task"`
+	// Cluster of task. defaults to the cluster of the service
+	Cluster string `mapstructure:"cluster"`
+	// Actions limits the hook to actions such as create or update. empty means every action
+	Actions []string `mapstructure:"actions"`
+	// Timeout in seconds
+	Timeout int64 `mapstructure:"timeout"`
+}
+
+// Hooks are hooks of both phases
+type Hooks struct {
+	Pre  []Hook `mapstructure:"pre"`
+	Post []Hook `mapstructure:"post"`
+}
+
+// ServiceHooks are hooks of one service. services are a list with names rather than a map keyed by name
+// since config keys are lowercased and ecs service names are case sensitive
+type ServiceHooks struct {
+	Name  string `mapstructure:"name"`
+	Hooks `mapstructure:",squash"`
+}
+
+// Config is global hooks and hooks per service
+type Config struct {
+	Hooks    `mapstructure:",squash"`
+	Services []ServiceHooks `mapstructure:"services"`
+}
+
+// Context describes the deployment to hooks
+type Context struct {
+	Phase             string   `json:"phase"`
+	Action            string   `json:"action"`
+	Service           string   `json:"service"`
+	Cluster           string   `json:"cluster"`
+	OldTaskDefinition string   `json:"oldTaskDefinition,omitempty"`
+	NewTaskDefinition string   `json:"newTaskDefinition,omitempty"`
+	OldImages         []string `json:"oldImages,omitempty"`
+	NewImages         []string `json:"newImages,omitempty"`
+	Outcome           string   `json:"outcome,omitempty"`
+	Error             string   `json:"error,omitempty"`
+}
+
+// Env returns context as environment variables. MORGAN_CONTEXT holds the whole context as json
+func (c Context) Env() []string {
+	data, _ := json.Marshal(c)
+
+	return []string{
+		"MORGAN_PHASE=" + c.Phase,
+		"MORGAN_ACTION=" + c.Action,
+		"MORGAN_SERVICE=" + c.Service,
+		"MORGAN_CLUSTER=" + c.Cluster,
+		"MORGAN_OLD_TASK_DEFINITION=" + c.OldTaskDefinition,
+		"MORGAN_NEW_TASK_DEFINITION=" + c.NewTaskDefinition,
+		"MORGAN_OLD_IMAGES=" + strings.Join(c.OldImages, ","),
+		"MORGAN_NEW_IMAGES=" + strings.Join(c.NewImages, ","),
+		"MORGAN_OUTCOME=" + c.Outcome,
+		"MORGAN_ERROR=" + c.Error,
+		"MORGAN_CONTEXT=" + string(data),
+	}
+}
+
+// For returns global hooks followed by hooks of the service for phase and action
+func (c Config) For(phase, action, service string) []Hook {
+	candidates := c.Hooks.phase(phase)
+	for _, s := range c.Services {
+		if s.Name == service {
+			candidates = append(candidates, s.phase(phase)...)
+		}
+	}
+
+	hooks := []Hook{}
+	for _, h := range candidates {
+		if h.appliesTo(action) {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+func (h Hooks) phase(phase string) []Hook {
+	if phase == PhasePre {
+		return append([]Hook{}, h.Pre...)
+	}
+	return append([]Hook{}, h.Post...)
+}
+
+func (h Hook) appliesTo(action string) bool {
+	if len(h.Actions) == 0 {
+		return true
+	}
+	for _, a := range h.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// String names hook in messages
+func (h Hook) String() string {
+	switch {
+	case len(h.Name) > 0:
+		return h.Name
+	case len(h.Command) > 0:
+		return h.Command
+	case len(h.URL) > 0:
+		return h.URL
+	default:
+		return "task " + h.Task
+	}
+}
+
+// Validate checks exactly one kind of hook is configured
+func (h Hook) Validate() error {
+	kinds := 0
+	for _, v := range []string{h.Command, h.URL, h.Task} {
+		if len(v) > 0 {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("hook %s must have exactly one of command, url and task", h)
+	}
+	return nil
+}
+
+// TimeoutDuration returns timeout of hook
+func (h Hook) TimeoutDuration() time.Duration {
+	if h.Timeout <= 0 {
+		return DefaultTimeout * time.Second
+	}
+	return time.Duration(h.Timeout) * time.Second
+}
+
+// Runner runs hooks. RunTask runs one-off ecs task and is supplied by the caller since it needs aws
+type Runner struct {
+	RunTask func(h Hook, c Context) (string, error)
+	HTTP    *http.Client
+}
+
+// Run runs hook and returns its output
+func (r Runner) Run(h Hook, c Context) (string, error) {
+	if err := h.Validate(); err != nil {
+		return "", err
+	}
+
+	switch {
+	case len(h.Command) > 0:
+		stdout, errout, err := execw.ExecEnv([]string{"sh", "-c", h.Command}, c.Env(), h.TimeoutDuration())
+		return stdout + errout, err
+	case len(h.URL) > 0:
+		return r.post(h, c)
+	default:
+		if r.RunTask == nil {
+			return "", errors.New("ecs task hooks are not supported")
+		}
+		return r.RunTask(h, c)
+	}
+}
+
+// post sends context as json to webhook. any status other than 2xx fails the hook
+func (r Runner) post(h Hook, c Context) (string, error) {
+	client := r.HTTP
+	if client == nil {
+		client = &http.Client{}
+	}
+	client.Timeout = h.TimeoutDuration()
+
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Post(h.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	body.ReadFrom(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return body.String(), fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return body.String(), nil
+}
@@ -0,0 +1,127 @@
+package hooks
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testConfig = Config{
+	Hooks: Hooks{
+		Pre:  []Hook{{Name: "global", Command: "true"}},
+		Post: []Hook{{Name: "notify", URL: "http://example.com", Actions: []string{"update"}}},
+	},
+	Services: []ServiceHooks{
+		{Name: "foo-svc", Hooks: Hooks{Pre: []Hook{{Name: "smoke", Task: "smoke-test"}}}},
+		{Name: "Bar-Svc", Hooks: Hooks{Pre: []Hook{{Name: "warm", Task: "warm-cache"}}}},
+	},
+}
+
+func TestFor(t *testing.T) {
+
+	tests := []struct {
+		phase, action, service string
+		want                   string
+	}{
+		{PhasePre, "update", "foo-svc", "global,smoke"},
+		{PhasePre, "update", "bar-svc", "global"},
+		{PhasePre, "update", "Bar-Svc", "global,warm"},
+		{PhasePost, "update", "foo-svc", "notify"},
+		{PhasePost, "delete", "foo-svc", ""},
+	}
+
+	for _, test := range tests {
+		names := []string{}
+		for _, h := range testConfig.For(test.phase, test.action, test.service) {
+			names = append(names, h.Name)
+		}
+		if got := strings.Join(names, ","); got != test.want {
+			t.Errorf("For(%s, %s, %s) = %s, want %s", test.phase, test.action, test.service, got, test.want)
+		}
+	}
+
+	// adding service hooks must not modify global hooks
+	if len(testConfig.Pre) != 1 {
+		t.Errorf("global hooks modified: %v", testConfig.Pre)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+
+	c := Context{Phase: PhasePre, Action: "update", Service: "foo-svc", NewImages: []string{"app:1", "sidecar:2"}}
+
+	output, err := Runner{}.Run(Hook{Command: `echo "$MORGAN_SERVICE $MORGAN_NEW_IMAGES"`}, c)
+	if err != nil || output != "foo-svc app:1,sidecar:2\n" {
+		t.Errorf("Run() = %q, %v", output, err)
+	}
+
+	if _, err := (Runner{}).Run(Hook{Command: "exit 3"}, c); err == nil {
+		t.Error("expected failing command to fail hook")
+	}
+
+	if _, err := (Runner{}).Run(Hook{Command: "exec sleep 5", Timeout: 1}, c); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout, got %v", err)
+	}
+}
+
+func TestRunWebhook(t *testing.T) {
+
+	var received Context
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		if received.Service == "bad-svc" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	output, err := Runner{}.Run(Hook{URL: server.URL}, Context{Phase: PhasePost, Service: "foo-svc", Outcome: "succeeded"})
+	if err != nil || output != "ok" {
+		t.Errorf("Run() = %q, %v", output, err)
+	}
+	if received.Service != "foo-svc" || received.Outcome != "succeeded" {
+		t.Errorf("received %+v", received)
+	}
+
+	if _, err := (Runner{}).Run(Hook{URL: server.URL}, Context{Service: "bad-svc"}); err == nil {
+		t.Error("expected error status to fail hook")
+	}
+}
+
+func TestValidate(t *testing.T) {
+
+	if err := (Hook{Command: "true", URL: "http://example.com"}).Validate(); err == nil {
+		t.Error("expected error for more than one kind")
+	}
+	if err := (Hook{Name: "empty"}).Validate(); err == nil {
+		t.Error("expected error for no kind")
+	}
+	if _, err := (Runner{}).Run(Hook{Task: "smoke-test"}, Context{}); err == nil {
+		t.Error("expected error without task runner")
+	}
+}
@@ -0,0 +1,145 @@
+package iampolicy
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Document is iam policy document
+type Document struct {
+	Version   string      `json:"Version"`
+	Statement []Statement `json:"Statement"`
+}
+
+// Statement is iam policy statement
+type Statement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource []string `json:"Resource"`
+}
+
+// shorthand grants actions on resources built from the shorthand value
+type shorthand struct {
+	actions   []string
+	resources func(value string) []string
+}
+
+var shorthands = map[string]shorthand{
+	"s3-read": {
+		actions: []string{"s3:GetObject", "s3:ListBucket"},
+		resources: func(bucket string) []string {
+			return []string{"arn:aws:s3:::" + bucket, "arn:aws:s3:::" + bucket + "/*"}
+		},
+	},
+	"s3-write": {
+		actions: []string{"s3:GetObject", "s3:ListBucket", "s3:PutObject", "s3:DeleteObject"},
+		resources: func(bucket string) []string {
+			return []string{"arn:aws:s3:::" + bucket, "arn:aws:s3:::" + bucket + "/*"}
+		},
+	},
+	"sqs": {
+		actions: []string{"sqs:SendMessage", "sqs:ReceiveMessage", "sqs:DeleteMessage", "sqs:ChangeMessageVisibility", "sqs:GetQueueAttributes", "sqs:GetQueueUrl"},
+		resources: func(queue string) []string {
+			return []string{"arn:aws:sqs:*:*:" + queue}
+		},
+	},
+	"sns": {
+		actions: []string{"sns:Publish"},
+		resources: func(topic string) []string {
+			return []string{"arn:aws:sns:*:*:" + topic}
+		},
+	},
+	"dynamodb": {
+		actions: []string{"dynamodb:GetItem", "dynamodb:BatchGetItem", "dynamodb:Query", "dynamodb:Scan", "dynamodb:PutItem", "dynamodb:UpdateItem", "dynamodb:DeleteItem", "dynamodb:BatchWriteItem"},
+		resources: func(table string) []string {
+			return []string{"arn:aws:dynamodb:*:*:table/" + table, "arn:aws:dynamodb:*:*:table/" + table + "/index/*"}
+		},
+	},
+	"ssm": {
+		actions: []string{"ssm:GetParameter", "ssm:GetParameters", "ssm:GetParametersByPath"},
+		resources: func(path string) []string {
+			return []string{"arn:aws:ssm:*:*:parameter/" + strings.TrimPrefix(path, "/") + "*"}
+		},
+	},
+	"secrets": {
+		actions: []string{"secretsmanager:GetSecretValue"},
+		resources: func(name string) []string {
+			// secrets manager appends random suffix to secret arns
+			return []string{"arn:aws:secretsmanager:*:*:secret:" + name + "-*"}
+		},
+	},
+}
+
+// Shorthands returns names of supported shorthands
+func Shorthands() []string {
+	names := []string{}
+	for name := range shorthands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parse converts shorthand such as s3-read:bucket or sqs:queue to policy statement
+func Parse(policy string) (Statement, error) {
+	tokens := strings.SplitN(policy, ":", 2)
+	if len(tokens) != 2 || len(tokens[1]) == 0 {
+		return Statement{}, fmt.Errorf("invalid policy %s. expected <kind>:<resource>", policy)
+	}
+
+	s, ok := shorthands[tokens[0]]
+	if !ok {
+		return Statement{}, fmt.Errorf("unknown policy kind %s. valid kinds are %s", tokens[0], strings.Join(Shorthands(), ","))
+	}
+
+	return Statement{
+		Effect:   "Allow",
+		Action:   s.actions,
+		Resource: s.resources(tokens[1]),
+	}, nil
+}
+
+// New returns policy document granting every shorthand
+func New(policies ...string) (Document, error) {
+	d := Document{Version: "2012-10-17"}
+
+	for _, policy := range policies {
+		s, err := Parse(policy)
+		if err != nil {
+			return d, err
+		}
+		d.Statement = append(d.Statement, s)
+	}
+
+	return d, nil
+}
+
+// String returns json of policy document
+func (d Document) String() string {
+	data, _ := json.Marshal(d)
+	return string(data)
+}
@@ -0,0 +1,68 @@
+package iampolicy
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	s, err := Parse("s3-read:my-bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(s.Resource, ",") != "arn:aws:s3:::my-bucket,arn:aws:s3:::my-bucket/*" {
+		t.Errorf("Resource = %v", s.Resource)
+	}
+	for _, action := range s.Action {
+		if action == "s3:PutObject" {
+			t.Error("s3-read must not allow writes")
+		}
+	}
+
+	s, _ = Parse("ssm:/foo-svc/")
+	if s.Resource[0] != "arn:aws:ssm:*:*:parameter/foo-svc/*" {
+		t.Errorf("Resource = %v", s.Resource)
+	}
+
+	for _, invalid := range []string{"s3-read", "s3-read:", "ec2:instance"} {
+		if _, err := Parse(invalid); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+
+	d, err := New("sqs:jobs", "sns:events")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["sqs:SendMessage","sqs:ReceiveMessage","sqs:DeleteMessage","sqs:ChangeMessageVisibility","sqs:GetQueueAttributes","sqs:GetQueueUrl"],"Resource":["arn:aws:sqs:*:*:jobs"]},{"Effect":"Allow","Action":["sns:Publish"],"Resource":["arn:aws:sns:*:*:events"]}]}`
+	if d.String() != want {
+		t.Errorf("String() = %s", d.String())
+	}
+}
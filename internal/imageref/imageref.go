@@ -0,0 +1,173 @@
+package imageref
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultRegistry is the registry used when image reference does not specify one
+const DefaultRegistry = "docker.io"
+
+var (
+	tagRegexp    = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+	pathRegexp   = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	domainRegexp = regexp.MustCompile(`^[a-zA-Z0-9](?:[a-zA-Z0-9.-]*[a-zA-Z0-9])?(?::[0-9]+)?$`)
+	ecrRegexp    = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+)
+
+// Reference is a parsed docker image reference. e.g. registry.local:5000/team/app:1.0.0@sha256:...
+type Reference struct {
+	// Domain is the registry host including port. empty if not specified in the reference
+	Domain string
+	// Path is the repository path without domain. e.g. team/app
+	Path   string
+	Tag    string
+	Digest string
+}
+
+// Parse parses image reference
+func Parse(s string) (Reference, error) {
+	ref := Reference{}
+
+	s = strings.TrimSpace(s)
+	if len(s) == 0 {
+		return ref, errors.New("empty image reference")
+	}
+
+	name := s
+
+	// digest is always at the end after @
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !digestRegexp.MatchString(ref.Digest) {
+			return ref, fmt.Errorf("invalid digest %q in image %s", ref.Digest, s)
+		}
+	}
+
+	// tag is after the last colon only if the colon comes after the last slash
+	// otherwise the colon separates registry host and port. e.g. registry.local:5000/app
+	lastSlash := strings.LastIndex(name, "/")
+	if i := strings.LastIndex(name, ":"); i > lastSlash {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+		if !tagRegexp.MatchString(ref.Tag) {
+			return ref, fmt.Errorf("invalid tag %q in image %s", ref.Tag, s)
+		}
+	}
+
+	// first component is a domain if it looks like a host name
+	if i := strings.Index(name, "/"); i >= 0 {
+		first := name[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" || strings.ToLower(first) != first {
+			ref.Domain = first
+			name = name[i+1:]
+			if !domainRegexp.MatchString(ref.Domain) {
+				return ref, fmt.Errorf("invalid registry %q in image %s", ref.Domain, s)
+			}
+		}
+	}
+
+	ref.Path = name
+	if !pathRegexp.MatchString(ref.Path) {
+		return ref, fmt.Errorf("invalid repository name %q in image %s", ref.Path, s)
+	}
+
+	return ref, nil
+}
+
+// Name returns the repository name as it was specified. e.g. registry.local:5000/app
+func (r Reference) Name() string {
+	if len(r.Domain) > 0 {
+		return r.Domain + "/" + r.Path
+	}
+	return r.Path
+}
+
+// String returns the image reference
+func (r Reference) String() string {
+	s := r.Name()
+	if len(r.Tag) > 0 {
+		s += ":" + r.Tag
+	}
+	if len(r.Digest) > 0 {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// WithTag returns copy of the reference with the tag replaced and digest removed
+func (r Reference) WithTag(tag string) Reference {
+	r.Tag = tag
+	r.Digest = ""
+	return r
+}
+
+// WithDigest returns copy of the reference pinned to the digest
+func (r Reference) WithDigest(digest string) Reference {
+	r.Digest = digest
+	return r
+}
+
+// TagOrDefault returns tag. latest is returned when neither tag or digest is specified
+func (r Reference) TagOrDefault() string {
+	if len(r.Tag) == 0 && len(r.Digest) == 0 {
+		return "latest"
+	}
+	return r.Tag
+}
+
+// Registry returns the registry host. docker.io is returned if reference does not specify one
+func (r Reference) Registry() string {
+	if len(r.Domain) == 0 {
+		return DefaultRegistry
+	}
+	return r.Domain
+}
+
+// Repository returns repository path as known to the registry. e.g. nginx becomes library/nginx on docker hub
+func (r Reference) Repository() string {
+	if r.Registry() == DefaultRegistry && !strings.Contains(r.Path, "/") {
+		return "library/" + r.Path
+	}
+	return r.Path
+}
+
+// IsECR checks to see if image is hosted in Amazon ECR
+func (r Reference) IsECR() bool {
+	return ecrRegexp.MatchString(r.Domain)
+}
+
+// ECRRegistryID returns aws account id and region of ECR registry
+func (r Reference) ECRRegistryID() (string, string) {
+	m := ecrRegexp.FindStringSubmatch(r.Domain)
+	if m == nil {
+		return "", ""
+	}
+	return m[1], m[2]
+}
@@ -0,0 +1,103 @@
+package imageref
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	digest := "sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac"
+
+	tests := []struct {
+		image      string
+		domain     string
+		path       string
+		tag        string
+		digest     string
+		repository string
+	}{
+		{"nginx", "", "nginx", "", "", "library/nginx"},
+		{"nginx:latest", "", "nginx", "latest", "", "library/nginx"},
+		{"7onetella/ref-api:1.0.0", "", "7onetella/ref-api", "1.0.0", "", "7onetella/ref-api"},
+		{"registry.local:5000/app", "registry.local:5000", "app", "", "", "app"},
+		{"registry.local:5000/team/app:1.2", "registry.local:5000", "team/app", "1.2", "", "team/app"},
+		{"localhost/app:dev", "localhost", "app", "dev", "", "app"},
+		{"app@" + digest, "", "app", "", digest, "library/app"},
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/api:v2@" + digest, "123456789012.dkr.ecr.us-east-1.amazonaws.com", "api", "v2", digest, "api"},
+	}
+
+	for _, test := range tests {
+		ref, err := Parse(test.image)
+		if err != nil {
+			t.Errorf("Parse(%q) failed: %v", test.image, err)
+			continue
+		}
+		if ref.Domain != test.domain || ref.Path != test.path || ref.Tag != test.tag || ref.Digest != test.digest {
+			t.Errorf("Parse(%q) = %+v", test.image, ref)
+		}
+		if ref.Repository() != test.repository {
+			t.Errorf("Parse(%q).Repository() = %s, expected %s", test.image, ref.Repository(), test.repository)
+		}
+		if ref.String() != test.image {
+			t.Errorf("Parse(%q).String() = %s", test.image, ref.String())
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+
+	for _, image := range []string{"", "app:", "App", "app@sha256:xyz", "app:bad/tag"} {
+		if _, err := Parse(image); err == nil {
+			t.Errorf("Parse(%q) expected error", image)
+		}
+	}
+}
+
+func TestWithTag(t *testing.T) {
+
+	ref, _ := Parse("registry.local:5000/app@sha256:4c0fdaa8b6341bfdeca5f18f7837462c80cff90527ee35ef185571e1c327beac")
+
+	if s := ref.WithTag("2.0.0").String(); s != "registry.local:5000/app:2.0.0" {
+		t.Errorf("WithTag = %s", s)
+	}
+}
+
+func TestIsECR(t *testing.T) {
+
+	ref, _ := Parse("123456789012.dkr.ecr.us-west-2.amazonaws.com/api:v2")
+	if !ref.IsECR() {
+		t.Error("expected ecr image")
+	}
+
+	account, region := ref.ECRRegistryID()
+	if account != "123456789012" || region != "us-west-2" {
+		t.Errorf("ECRRegistryID() = %s, %s", account, region)
+	}
+
+	ref, _ = Parse("nginx")
+	if ref.IsECR() {
+		t.Error("nginx is not an ecr image")
+	}
+}
@@ -0,0 +1,285 @@
+package k8s
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"fmt"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// the types below are the subset of kubernetes objects morgan generates. they marshal in the same shape as kubectl expects
+
+// Metadata is object metadata
+type Metadata struct {
+	Name        string            `yaml:"name"`
+	Namespace   string            `yaml:"namespace,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Deployment is apps/v1 Deployment
+type Deployment struct {
+	APIVersion string         `yaml:"apiVersion"`
+	Kind       string         `yaml:"kind"`
+	Metadata   Metadata       `yaml:"metadata"`
+	Spec       DeploymentSpec `yaml:"spec"`
+}
+
+// DeploymentSpec is spec of Deployment
+type DeploymentSpec struct {
+	Replicas int64               `yaml:"replicas"`
+	Selector LabelSelector       `yaml:"selector"`
+	Strategy *DeploymentStrategy `yaml:"strategy,omitempty"`
+	Template PodTemplate         `yaml:"template"`
+}
+
+// LabelSelector selects pods by labels
+type LabelSelector struct {
+	MatchLabels map[string]string `yaml:"matchLabels"`
+}
+
+// DeploymentStrategy is rolling update strategy
+type DeploymentStrategy struct {
+	Type          string         `yaml:"type"`
+	RollingUpdate *RollingUpdate `yaml:"rollingUpdate,omitempty"`
+}
+
+// RollingUpdate limits pods unavailable and surged during rollout
+type RollingUpdate struct {
+	MaxUnavailable string `yaml:"maxUnavailable,omitempty"`
+	MaxSurge       string `yaml:"maxSurge,omitempty"`
+}
+
+// PodTemplate is pod template of Deployment
+type PodTemplate struct {
+	Metadata Metadata `yaml:"metadata"`
+	Spec     PodSpec  `yaml:"spec"`
+}
+
+// PodSpec is spec of pod
+type PodSpec struct {
+	Containers []Container `yaml:"containers"`
+	Volumes    []Volume    `yaml:"volumes,omitempty"`
+}
+
+// Container is container of pod
+type Container struct {
+	Name            string           `yaml:"name"`
+	Image           string           `yaml:"image"`
+	Command         []string         `yaml:"command,omitempty"`
+	Args            []string         `yaml:"args,omitempty"`
+	WorkingDir      string           `yaml:"workingDir,omitempty"`
+	Ports           []ContainerPort  `yaml:"ports,omitempty"`
+	EnvFrom         []EnvFromSource  `yaml:"envFrom,omitempty"`
+	Env             []EnvVar         `yaml:"env,omitempty"`
+	Resources       *Resources       `yaml:"resources,omitempty"`
+	VolumeMounts    []VolumeMount    `yaml:"volumeMounts,omitempty"`
+	LivenessProbe   *Probe           `yaml:"livenessProbe,omitempty"`
+	ReadinessProbe  *Probe           `yaml:"readinessProbe,omitempty"`
+	SecurityContext *SecurityContext `yaml:"securityContext,omitempty"`
+}
+
+// ContainerPort is port exposed by container
+type ContainerPort struct {
+	Name          string `yaml:"name,omitempty"`
+	ContainerPort int64  `yaml:"containerPort"`
+	Protocol      string `yaml:"protocol,omitempty"`
+}
+
+// EnvFromSource populates environment from config map
+type EnvFromSource struct {
+	ConfigMapRef *LocalObjectReference `yaml:"configMapRef,omitempty"`
+	SecretRef    *LocalObjectReference `yaml:"secretRef,omitempty"`
+}
+
+// LocalObjectReference refers to object in the same namespace
+type LocalObjectReference struct {
+	Name string `yaml:"name"`
+}
+
+// EnvVar is environment variable
+type EnvVar struct {
+	Name      string        `yaml:"name"`
+	Value     string        `yaml:"value,omitempty"`
+	ValueFrom *EnvVarSource `yaml:"valueFrom,omitempty"`
+}
+
+// EnvVarSource refers to key of secret
+type EnvVarSource struct {
+	SecretKeyRef *KeySelector `yaml:"secretKeyRef,omitempty"`
+}
+
+// KeySelector selects key of secret
+type KeySelector struct {
+	Name string `yaml:"name"`
+	Key  string `yaml:"key"`
+}
+
+// Resources are requests and limits of container
+type Resources struct {
+	Requests map[string]string `yaml:"requests,omitempty"`
+	Limits   map[string]string `yaml:"limits,omitempty"`
+}
+
+// VolumeMount mounts volume into container
+type VolumeMount struct {
+	Name      string `yaml:"name"`
+	MountPath string `yaml:"mountPath"`
+	ReadOnly  bool   `yaml:"readOnly,omitempty"`
+}
+
+// Probe is exec probe
+type Probe struct {
+	Exec                *ExecAction `yaml:"exec"`
+	InitialDelaySeconds int64       `yaml:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int64       `yaml:"periodSeconds,omitempty"`
+	TimeoutSeconds      int64       `yaml:"timeoutSeconds,omitempty"`
+	FailureThreshold    int64       `yaml:"failureThreshold,omitempty"`
+}
+
+// ExecAction runs command in container
+type ExecAction struct {
+	Command []string `yaml:"command"`
+}
+
+// SecurityContext is security context of container
+type SecurityContext struct {
+	Privileged bool `yaml:"privileged,omitempty"`
+}
+
+// Volume is pod volume
+type Volume struct {
+	Name                  string                 `yaml:"name"`
+	HostPath              *HostPathVolume        `yaml:"hostPath,omitempty"`
+	EmptyDir              *EmptyDirVolume        `yaml:"emptyDir,omitempty"`
+	PersistentVolumeClaim *PersistentVolumeClaim `yaml:"persistentVolumeClaim,omitempty"`
+}
+
+// HostPathVolume mounts path of the node
+type HostPathVolume struct {
+	Path string `yaml:"path"`
+}
+
+// EmptyDirVolume lives as long as the pod
+type EmptyDirVolume struct {
+	Medium    string `yaml:"medium,omitempty"`
+	SizeLimit string `yaml:"sizeLimit,omitempty"`
+}
+
+// PersistentVolumeClaim refers to claim by name
+type PersistentVolumeClaim struct {
+	ClaimName string `yaml:"claimName"`
+}
+
+// Service is v1 Service
+type Service struct {
+	APIVersion string      `yaml:"apiVersion"`
+	Kind       string      `yaml:"kind"`
+	Metadata   Metadata    `yaml:"metadata"`
+	Spec       ServiceSpec `yaml:"spec"`
+}
+
+// ServiceSpec is spec of Service
+type ServiceSpec struct {
+	Selector map[string]string `yaml:"selector"`
+	Ports    []ServicePort     `yaml:"ports"`
+}
+
+// ServicePort is port of Service
+type ServicePort struct {
+	Name       string `yaml:"name,omitempty"`
+	Port       int64  `yaml:"port"`
+	TargetPort int64  `yaml:"targetPort"`
+	Protocol   string `yaml:"protocol,omitempty"`
+}
+
+// ConfigMap is v1 ConfigMap
+type ConfigMap struct {
+	APIVersion string            `yaml:"apiVersion"`
+	Kind       string            `yaml:"kind"`
+	Metadata   Metadata          `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+}
+
+// NewDeployment initializes Deployment selecting pods labeled app: name
+func NewDeployment(name, namespace string, replicas int64) *Deployment {
+	labels := map[string]string{"app": name}
+	return &Deployment{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Metadata:   Metadata{Name: name, Namespace: namespace, Labels: labels},
+		Spec: DeploymentSpec{
+			Replicas: replicas,
+			Selector: LabelSelector{MatchLabels: labels},
+			Template: PodTemplate{Metadata: Metadata{Name: name, Labels: labels}},
+		},
+	}
+}
+
+// NewService initializes Service selecting pods labeled app: name
+func NewService(name, namespace string) *Service {
+	return &Service{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Metadata:   Metadata{Name: name, Namespace: namespace, Labels: map[string]string{"app": name}},
+		Spec:       ServiceSpec{Selector: map[string]string{"app": name}},
+	}
+}
+
+// NewConfigMap initializes ConfigMap
+func NewConfigMap(name, namespace string, data map[string]string) *ConfigMap {
+	return &ConfigMap{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Metadata:   Metadata{Name: name, Namespace: namespace},
+		Data:       data,
+	}
+}
+
+// CPU converts ecs cpu units to millicores. 1024 units is one cpu
+func CPU(units int64) string {
+	return fmt.Sprintf("%dm", units*1000/1024)
+}
+
+// Memory formats MiB as kubernetes quantity
+func Memory(mib int64) string {
+	return fmt.Sprintf("%dMi", mib)
+}
+
+// Marshal marshals objects into multi document yaml
+func Marshal(objects ...interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, o := range objects {
+		data, err := yaml.Marshal(o)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
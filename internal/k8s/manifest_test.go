@@ -0,0 +1,54 @@
+package k8s
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+
+	d := NewDeployment("hello-world", "apps", 2)
+	d.Spec.Template.Spec.Containers = []Container{
+		{
+			Name:      "hello-world",
+			Image:     "nginx:1.17",
+			Resources: &Resources{Requests: map[string]string{"cpu": CPU(256), "memory": Memory(512)}},
+		},
+	}
+	s := NewService("hello-world", "apps")
+	s.Spec.Ports = []ServicePort{{Port: 80, TargetPort: 8080}}
+
+	data, err := Marshal(d, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := string(data)
+	for _, expected := range []string{"kind: Deployment", "replicas: 2", "cpu: 250m", "memory: 512Mi", "---\napiVersion: v1\nkind: Service", "targetPort: 8080"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("missing %q in\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package naming
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Kinds of names the policy has rules for
+const (
+	KindService = "service"
+	KindDNS     = "dns"
+	KindUser    = "user"
+)
+
+// Rule constrains names of one kind
+type Rule struct {
+	// Pattern such as {team}-{app}-{env}. empty pattern accepts any name
+	Pattern string `mapstructure:"pattern"`
+	// MaxLength is the maximum number of characters
+	MaxLength int `mapstructure:"maxLength"`
+	// Allowed is the set of allowed characters in regular expression character class syntax. e.g. a-z0-9-
+	Allowed string `mapstructure:"allowed"`
+	// Values restricts parts of the pattern to a list of values. e.g. env: [dev, qa, prod]
+	Values map[string][]string `mapstructure:"values"`
+}
+
+// Policy is naming rules by kind
+type Policy map[string]Rule
+
+// DefaultPolicy returns rules matching aws and ldap limits
+func DefaultPolicy() Policy {
+	return Policy{
+		// ecs service names, task definition families and container names share the same limits
+		KindService: {MaxLength: 255, Allowed: "a-zA-Z0-9_-"},
+		KindDNS:     {MaxLength: 253, Allowed: "a-z0-9.-"},
+		KindUser:    {MaxLength: 32, Allowed: "a-z0-9._-"},
+	}
+}
+
+// Rule returns rule of kind. limits not configured fall back to the default policy
+func (p Policy) Rule(kind string) Rule {
+	rule := p[kind]
+	defaults := DefaultPolicy()[kind]
+
+	if rule.MaxLength == 0 {
+		rule.MaxLength = defaults.MaxLength
+	}
+	if len(rule.Allowed) == 0 {
+		rule.Allowed = defaults.Allowed
+	}
+
+	return rule
+}
+
+// Validate validates name of kind against the policy
+func (p Policy) Validate(kind, name string) error {
+	rule := p.Rule(kind)
+
+	if len(name) == 0 {
+		return fmt.Errorf("%s name is empty", kind)
+	}
+
+	if rule.MaxLength > 0 && len(name) > rule.MaxLength {
+		return fmt.Errorf("%s name %s is %d characters long. maximum is %d", kind, name, len(name), rule.MaxLength)
+	}
+
+	if len(rule.Allowed) > 0 {
+		allowed, err := regexp.Compile("^[" + rule.Allowed + "]+$")
+		if err != nil {
+			return fmt.Errorf("invalid allowed characters %s: %v", rule.Allowed, err)
+		}
+		if !allowed.MatchString(name) {
+			return fmt.Errorf("%s name %s may only contain %s", kind, name, rule.Allowed)
+		}
+	}
+
+	if len(rule.Pattern) > 0 {
+		re, err := rule.regexp()
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(name) {
+			return fmt.Errorf("%s name %s does not follow %s%s", kind, name, rule.Pattern, rule.describeValues())
+		}
+	}
+
+	switch kind {
+	case KindService:
+		if !isLetterOrDigit(name[0]) {
+			return fmt.Errorf("%s name %s must start with a letter or digit", kind, name)
+		}
+	case KindDNS:
+		return validateHostname(name)
+	}
+
+	return nil
+}
+
+// Generate generates name of kind from parts and validates it
+func (p Policy) Generate(kind string, parts map[string]string) (string, error) {
+	rule := p.Rule(kind)
+
+	if len(rule.Pattern) == 0 {
+		return "", fmt.Errorf("naming policy has no pattern for %s names", kind)
+	}
+
+	var err error
+	name := placeholderRegexp.ReplaceAllStringFunc(rule.Pattern, func(placeholder string) string {
+		part := placeholder[1 : len(placeholder)-1]
+		value, ok := parts[part]
+		if (!ok || len(value) == 0) && err == nil {
+			err = fmt.Errorf("%s is required by naming pattern %s", part, rule.Pattern)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return name, p.Validate(kind, name)
+}
+
+// Placeholders returns names of parts in pattern of kind
+func (p Policy) Placeholders(kind string) []string {
+	parts := []string{}
+	for _, m := range placeholderRegexp.FindAllString(p.Rule(kind).Pattern, -1) {
+		parts = append(parts, m[1:len(m)-1])
+	}
+	return parts
+}
+
+var placeholderRegexp = regexp.MustCompile(`\{[a-zA-Z0-9_]+\}`)
+
+// regexp converts pattern to regular expression. parts with values only match those values
+func (r Rule) regexp() (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+
+	last := 0
+	for _, loc := range placeholderRegexp.FindAllStringIndex(r.Pattern, -1) {
+		b.WriteString(regexp.QuoteMeta(r.Pattern[last:loc[0]]))
+
+		part := r.Pattern[loc[0]+1 : loc[1]-1]
+		if values, ok := r.Values[part]; ok && len(values) > 0 {
+			quoted := []string{}
+			for _, v := range values {
+				quoted = append(quoted, regexp.QuoteMeta(v))
+			}
+			b.WriteString("(?:" + strings.Join(quoted, "|") + ")")
+		} else {
+			b.WriteString("(?:.+?)")
+		}
+
+		last = loc[1]
+	}
+	b.WriteString(regexp.QuoteMeta(r.Pattern[last:]))
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid naming pattern %s: %v", r.Pattern, err)
+	}
+	return re, nil
+}
+
+func (r Rule) describeValues() string {
+	if len(r.Values) == 0 {
+		return ""
+	}
+
+	parts := []string{}
+	for part, values := range r.Values {
+		parts = append(parts, part+" one of "+strings.Join(values, "|"))
+	}
+	sort.Strings(parts)
+
+	return " with " + strings.Join(parts, ", ")
+}
+
+// validateHostname checks rfc 1123 label rules
+func validateHostname(name string) error {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if len(label) == 0 {
+			return fmt.Errorf("dns name %s has an empty label", name)
+		}
+		if len(label) > 63 {
+			return fmt.Errorf("dns label %s is longer than 63 characters", label)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("dns label %s can not start or end with hyphen", label)
+		}
+	}
+	return nil
+}
+
+func isLetterOrDigit(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
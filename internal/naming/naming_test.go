@@ -0,0 +1,100 @@
+package naming
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"testing"
+)
+
+var policy = Policy{
+	KindService: {
+		Pattern:   "{team}-{app}-{env}",
+		MaxLength: 32,
+		Allowed:   "a-z0-9-",
+		Values:    map[string][]string{"env": {"dev", "qa", "prod"}},
+	},
+	KindDNS: {
+		Pattern: "{app}.{env}.example.com",
+	},
+}
+
+func TestValidate(t *testing.T) {
+
+	tests := []struct {
+		kind  string
+		name  string
+		valid bool
+	}{
+		{KindService, "pay-api-prod", true},
+		{KindService, "pay-order-api-prod", true},
+		{KindService, "pay-api-staging", false},
+		{KindService, "pay-api", false},
+		{KindService, "Pay-api-prod", false},
+		{KindService, "payments-reconciliation-worker-prod", false},
+		{KindDNS, "api.dev.example.com", true},
+		{KindDNS, "api.dev.example.org", false},
+		{KindDNS, "-api.dev.example.com", false},
+		{KindUser, "smithj", true},
+		{KindUser, "smith j", false},
+		{KindUser, "", false},
+	}
+
+	for _, test := range tests {
+		err := policy.Validate(test.kind, test.name)
+		if test.valid && err != nil {
+			t.Errorf("Validate(%s, %s) = %v", test.kind, test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("Validate(%s, %s) expected error", test.kind, test.name)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+
+	name, err := policy.Generate(KindService, map[string]string{"team": "pay", "app": "api", "env": "qa"})
+	if err != nil || name != "pay-api-qa" {
+		t.Errorf("Generate() = %s, %v", name, err)
+	}
+
+	_, err = policy.Generate(KindService, map[string]string{"team": "pay", "app": "api"})
+	if err == nil {
+		t.Error("expected error for missing env")
+	}
+
+	_, err = policy.Generate(KindUser, map[string]string{"team": "pay"})
+	if err == nil {
+		t.Error("expected error for kind without pattern")
+	}
+}
+
+func TestDefaultPolicy(t *testing.T) {
+
+	if err := DefaultPolicy().Validate(KindService, "hello-world_v2"); err != nil {
+		t.Error(err)
+	}
+
+	if err := (Policy{}).Validate(KindService, "-hello"); err == nil {
+		t.Error("expected error for name starting with hyphen")
+	}
+}
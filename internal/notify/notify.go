@@ -0,0 +1,245 @@
+package notify
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// TypeSlack posts slack incoming webhook message
+	TypeSlack = "slack"
+	// TypeMattermost posts mattermost incoming webhook message. mattermost accepts slack attachments
+	TypeMattermost = "mattermost"
+	// TypeHTTP posts event as json
+	TypeHTTP = "http"
+)
+
+// DefaultTimeout applies to webhooks without timeout
+const DefaultTimeout = 5
+
+// Event is the outcome of an action such as ecs update or ec2 stop
+type Event struct {
+	Kind      string        `json:"kind"`
+	Action    string        `json:"action"`
+	Target    string        `json:"target"`
+	Cluster   string        `json:"cluster,omitempty"`
+	User      string        `json:"user"`
+	OldImages []string      `json:"oldImages,omitempty"`
+	NewImages []string      `json:"newImages,omitempty"`
+	Duration  time.Duration `json:"-"`
+	Succeeded bool          `json:"succeeded"`
+	Error     string        `json:"error,omitempty"`
+}
+
+// Webhook is an endpoint events are posted to
+type Webhook struct {
+	Type string `mapstructure:"type"`
+	URL  string `mapstructure:"url"`
+	// Actions limits webhook to actions such as update or rollback. empty means every action
+	Actions []string `mapstructure:"actions"`
+	// Timeout in seconds
+	Timeout int64 `mapstructure:"timeout"`
+}
+
+// Config is webhooks to notify
+type Config struct {
+	Webhooks []Webhook `mapstructure:"webhooks"`
+}
+
+// MarshalJSON posts duration in whole seconds rather than nanoseconds
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	return json.Marshal(struct {
+		event
+		Duration int64 `json:"duration"`
+	}{event(e), int64(e.Duration.Round(time.Second) / time.Second)})
+}
+
+// Summary returns one line description of event. e.g. alice update foo-svc in api-cluster app:1.0.0 → app:1.1.0 (42s)
+func (e Event) Summary() string {
+	verb := e.Action
+	if !e.Succeeded {
+		verb = e.Action + " failed for"
+	}
+
+	s := fmt.Sprintf("%s %s %s", e.User, verb, e.Target)
+	if len(e.Cluster) > 0 {
+		s += " in " + e.Cluster
+	}
+	if change := e.ImageChange(); len(change) > 0 {
+		s += " " + change
+	}
+	s += fmt.Sprintf(" (%s)", e.Duration.Round(time.Second))
+
+	return s
+}
+
+// ImageChange returns old → new images. only new images are returned when they did not change
+func (e Event) ImageChange() string {
+	old, new := strings.Join(e.OldImages, ","), strings.Join(e.NewImages, ",")
+	switch {
+	case len(old) > 0 && len(new) > 0 && old != new:
+		return old + " → " + new
+	case len(new) > 0:
+		return new
+	default:
+		return old
+	}
+}
+
+// Enabled checks to see if any webhook wants to be notified of action
+func (c Config) Enabled(action string) bool {
+	for _, w := range c.Webhooks {
+		if w.appliesTo(action) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo checks to see if webhook wants to be notified of action
+func (w Webhook) appliesTo(action string) bool {
+	if len(w.Actions) == 0 {
+		return true
+	}
+	for _, a := range w.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// payload returns body posted to webhook
+func (w Webhook) payload(e Event) (interface{}, error) {
+	switch strings.ToLower(w.Type) {
+	case TypeSlack, TypeMattermost:
+		return slackMessage(e), nil
+	case TypeHTTP, "":
+		return e, nil
+	default:
+		return nil, fmt.Errorf("unknown webhook type %s. must be slack, mattermost or http", w.Type)
+	}
+}
+
+// slackMessage formats event as slack incoming webhook message with attachment fields
+func slackMessage(e Event) map[string]interface{} {
+	color := "good"
+	if !e.Succeeded {
+		color = "danger"
+	}
+
+	field := func(title, value string, short bool) map[string]interface{} {
+		return map[string]interface{}{"title": title, "value": value, "short": short}
+	}
+
+	fields := []map[string]interface{}{
+		field("Target", e.Target, true),
+	}
+	if len(e.Cluster) > 0 {
+		fields = append(fields, field("Cluster", e.Cluster, true))
+	}
+	fields = append(fields, field("User", e.User, true), field("Duration", e.Duration.Round(time.Second).String(), true))
+	if change := e.ImageChange(); len(change) > 0 {
+		fields = append(fields, field("Image", change, false))
+	}
+	if len(e.Error) > 0 {
+		fields = append(fields, field("Error", e.Error, false))
+	}
+
+	return map[string]interface{}{
+		"text": e.Summary(),
+		"attachments": []map[string]interface{}{
+			{
+				"fallback": e.Summary(),
+				"color":    color,
+				"fields":   fields,
+			},
+		},
+	}
+}
+
+// Send posts event to every webhook that applies concurrently. each webhook is given its own timeout
+// so a slow or down webhook delays the caller by at most the longest timeout. errors are returned for reporting only
+func Send(config Config, e Event) []error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := []error{}
+
+	for _, w := range config.Webhooks {
+		if !w.appliesTo(e.Action) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(w Webhook) {
+			defer wg.Done()
+			if err := post(w, e); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("%s: %v", w.URL, err))
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	return errs
+}
+
+func post(w Webhook, e Event) error {
+	payload, err := w.payload(e)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	timeout := time.Duration(w.Timeout) * time.Second
+	if w.Timeout <= 0 {
+		timeout = DefaultTimeout * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Post(w.URL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
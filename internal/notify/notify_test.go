@@ -0,0 +1,141 @@
+package notify
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var testEvent = Event{
+	Kind:      "ecs",
+	Action:    "update",
+	Target:    "foo-svc",
+	Cluster:   "api-cluster",
+	User:      "alice",
+	OldImages: []string{"app:1.0.0"},
+	NewImages: []string{"app:1.1.0"},
+	Duration:  42 * time.Second,
+	Succeeded: true,
+}
+
+func TestSummary(t *testing.T) {
+
+	if s := testEvent.Summary(); s != "alice update foo-svc in api-cluster app:1.0.0 → app:1.1.0 (42s)" {
+		t.Errorf("Summary() = %s", s)
+	}
+
+	failed := testEvent
+	failed.Succeeded = false
+	failed.NewImages = []string{"app:1.0.0"}
+	if s := failed.Summary(); s != "alice update failed for foo-svc in api-cluster app:1.0.0 (42s)" {
+		t.Errorf("Summary() = %s", s)
+	}
+}
+
+func TestSend(t *testing.T) {
+
+	var mu sync.Mutex
+	received := map[string]map[string]interface{}{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		mu.Lock()
+		received[r.URL.Path] = body
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/slow":
+			time.Sleep(3 * time.Second)
+		}
+	}))
+	defer server.Close()
+
+	config := Config{Webhooks: []Webhook{
+		{Type: TypeSlack, URL: server.URL + "/slack"},
+		{Type: TypeHTTP, URL: server.URL + "/generic"},
+		{Type: TypeHTTP, URL: server.URL + "/rollbacks", Actions: []string{"rollback"}},
+		{Type: TypeHTTP, URL: server.URL + "/down"},
+		{Type: TypeHTTP, URL: server.URL + "/slow", Timeout: 1},
+	}}
+
+	start := time.Now()
+	errs := Send(config, testEvent)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Send() took %s, slow webhook was not timed out", elapsed)
+	}
+
+	if len(errs) != 2 {
+		t.Errorf("Send() errors = %v", errs)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if received["/slack"]["text"] != testEvent.Summary() {
+		t.Errorf("slack message = %v", received["/slack"])
+	}
+	attachments, _ := received["/slack"]["attachments"].([]interface{})
+	if len(attachments) != 1 || !strings.Contains(toJSON(attachments), `"color":"good"`) {
+		t.Errorf("slack attachments = %v", attachments)
+	}
+
+	if received["/generic"]["target"] != "foo-svc" || received["/generic"]["succeeded"] != true || received["/generic"]["duration"] != float64(42) {
+		t.Errorf("generic event = %v", received["/generic"])
+	}
+
+	if _, ok := received["/rollbacks"]; ok {
+		t.Error("webhook limited to rollback was notified of update")
+	}
+}
+
+func TestEnabled(t *testing.T) {
+
+	config := Config{Webhooks: []Webhook{{URL: "http://127.0.0.1:1", Actions: []string{"rollback"}}}}
+
+	if !config.Enabled("rollback") || config.Enabled("update") || (Config{}).Enabled("update") {
+		t.Error("Enabled() did not honor webhook actions")
+	}
+}
+
+func TestSendUnknownType(t *testing.T) {
+
+	errs := Send(Config{Webhooks: []Webhook{{Type: "irc", URL: "http://127.0.0.1:1"}}}, testEvent)
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "unknown webhook type irc") {
+		t.Errorf("Send() errors = %v", errs)
+	}
+}
+
+func toJSON(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
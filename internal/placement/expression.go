@@ -0,0 +1,259 @@
+package placement
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxExpressionLength is the maximum length of cluster query language expression
+const MaxExpressionLength = 2000
+
+var subjects = map[string]bool{
+	"agentConnected":    true,
+	"agentVersion":      true,
+	"ec2InstanceId":     true,
+	"registeredAt":      true,
+	"runningTasksCount": true,
+}
+
+var operators = map[string]bool{
+	"==": true, "equals": true,
+	"!=": true, "not_equals": true,
+	">": true, "greater_than": true,
+	">=": true, "greater_than_equal": true,
+	"<": true, "less_than": true,
+	"<=": true, "less_than_equal": true,
+	"=~": true, "matches": true,
+	"!~": true, "not_matches": true,
+}
+
+var unaryOperators = map[string]bool{
+	"exists": true, "!exists": true, "not_exists": true,
+}
+
+var listOperators = map[string]bool{
+	"in": true, "not_in": true,
+}
+
+var logicalOperators = map[string]bool{
+	"and": true, "&&": true,
+	"or": true, "||": true,
+}
+
+// Validate validates cluster query language expression used by memberOf placement constraints
+// https://docs.aws.amazon.com/AmazonECS/latest/developerguide/cluster-query-language.html
+func Validate(expression string) error {
+	if len(strings.TrimSpace(expression)) == 0 {
+		return errors.New("empty expression")
+	}
+
+	if len(expression) > MaxExpressionLength {
+		return fmt.Errorf("expression is longer than %d characters", MaxExpressionLength)
+	}
+
+	tokens, err := tokenize(expression)
+	if err != nil {
+		return err
+	}
+
+	p := &parser{tokens: tokens}
+	if err := p.expr(); err != nil {
+		return err
+	}
+
+	if !p.done() {
+		return fmt.Errorf("unexpected %q", p.peek())
+	}
+
+	return nil
+}
+
+func tokenize(s string) ([]string, error) {
+	tokens := []string{}
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == '!' && (i+1 == len(s) || s[i+1] == '(' || s[i+1] == ' '):
+			tokens = append(tokens, "!")
+			i++
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, errors.New("missing closing ]")
+			}
+			tokens = append(tokens, s[i:i+end+1])
+			i += end + 1
+		default:
+			start := i
+			for i < len(s) && s[i] != ' ' && s[i] != '\t' && s[i] != '\n' && s[i] != '(' && s[i] != ')' {
+				i++
+			}
+			tokens = append(tokens, s[start:i])
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *parser) expr() error {
+	if err := p.term(); err != nil {
+		return err
+	}
+
+	for logicalOperators[p.peek()] {
+		p.next()
+		if err := p.term(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) term() error {
+	if t := p.peek(); t == "not" || t == "!" {
+		p.next()
+	}
+
+	if p.peek() == "(" {
+		p.next()
+		if err := p.expr(); err != nil {
+			return err
+		}
+		if p.next() != ")" {
+			return errors.New("missing closing )")
+		}
+		return nil
+	}
+
+	return p.condition()
+}
+
+func (p *parser) condition() error {
+	subject := p.next()
+	if len(subject) == 0 {
+		return errors.New("expression ended where subject was expected")
+	}
+	if err := validateSubject(subject); err != nil {
+		return err
+	}
+
+	op := p.next()
+	switch {
+	case len(op) == 0:
+		return fmt.Errorf("missing operator after %s", subject)
+	case unaryOperators[op]:
+		return nil
+	case listOperators[op]:
+		return p.list(op)
+	case operators[op]:
+		arg := p.next()
+		if len(arg) == 0 || arg == "(" || arg == ")" || logicalOperators[arg] {
+			return fmt.Errorf("missing argument after %s %s", subject, op)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown operator %q after %s", op, subject)
+	}
+}
+
+// list accepts [a, b] or (a, b) argument lists of in and not_in
+func (p *parser) list(op string) error {
+	t := p.next()
+	if strings.HasPrefix(t, "[") {
+		if len(strings.TrimSpace(t[1:len(t)-1])) == 0 {
+			return fmt.Errorf("empty list after %s", op)
+		}
+		return nil
+	}
+
+	if t != "(" {
+		return fmt.Errorf("expected list after %s", op)
+	}
+
+	count := 0
+	for {
+		t = p.next()
+		switch t {
+		case "":
+			return errors.New("missing closing )")
+		case ")":
+			if count == 0 {
+				return fmt.Errorf("empty list after %s", op)
+			}
+			return nil
+		default:
+			count++
+		}
+	}
+}
+
+func validateSubject(subject string) error {
+	if subjects[subject] {
+		return nil
+	}
+
+	for _, prefix := range []string{"attribute:", "task:"} {
+		if strings.HasPrefix(subject, prefix) {
+			if len(subject) == len(prefix) {
+				return fmt.Errorf("missing name in subject %s", subject)
+			}
+			if prefix == "task:" && subject != "task:group" {
+				return fmt.Errorf("unknown subject %s. only task:group is supported", subject)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown subject %q. expected attribute:<name>, task:group, agentConnected, agentVersion, ec2InstanceId, registeredAt or runningTasksCount", subject)
+}
@@ -0,0 +1,67 @@
+package placement
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+
+	valid := []string{
+		"attribute:ecs.instance-type =~ t3.*",
+		"attribute:ecs.availability-zone in [us-east-1a, us-east-1b]",
+		"attribute:ecs.availability-zone in (us-east-1a, us-east-1b)",
+		"task:group == service:production",
+		"attribute:ecs.os-type exists",
+		"not(attribute:ecs.instance-type =~ t2.*) and runningTasksCount < 10",
+		"(attribute:stack == prod || attribute:stack == stage) && agentConnected == true",
+	}
+
+	for _, expression := range valid {
+		if err := Validate(expression); err != nil {
+			t.Errorf("Validate(%q) failed: %v", expression, err)
+		}
+	}
+}
+
+func TestValidateInvalid(t *testing.T) {
+
+	invalid := []string{
+		"",
+		"attribute:ecs.instance-type",
+		"attribute:ecs.instance-type =~",
+		"instance-type == t3.micro",
+		"attribute:ecs.instance-type ~= t3.*",
+		"(attribute:ecs.instance-type == t3.micro",
+		"attribute:ecs.availability-zone in []",
+		"task:family == api",
+		"runningTasksCount < 10 and",
+	}
+
+	for _, expression := range invalid {
+		if err := Validate(expression); err == nil {
+			t.Errorf("Validate(%q) expected error", expression)
+		}
+	}
+}
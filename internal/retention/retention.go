@@ -0,0 +1,75 @@
+package retention
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"sort"
+	"time"
+)
+
+// Image is an image in a repository
+type Image struct {
+	Digest   string
+	Tags     []string
+	PushedAt time.Time
+}
+
+// Protected is a set of tags and digests that must never be deleted
+type Protected map[string]bool
+
+// protects checks to see if image digest or any of its tags is protected
+func (p Protected) protects(image Image) bool {
+	if p[image.Digest] {
+		return true
+	}
+	for _, tag := range image.Tags {
+		if p[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns images to delete. the newest keep images and protected images are retained.
+// protected images do not count towards keep
+func Select(images []Image, keep int, protected Protected) []Image {
+	sorted := append([]Image{}, images...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].PushedAt.After(sorted[j].PushedAt)
+	})
+
+	deletes := []Image{}
+	kept := 0
+	for _, image := range sorted {
+		if protected.protects(image) {
+			continue
+		}
+		if kept < keep {
+			kept++
+			continue
+		}
+		deletes = append(deletes, image)
+	}
+
+	return deletes
+}
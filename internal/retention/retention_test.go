@@ -0,0 +1,71 @@
+package retention
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelect(t *testing.T) {
+
+	now := time.Now()
+	images := []Image{
+		{Digest: "sha256:1", Tags: []string{"1.0.0"}, PushedAt: now.Add(-5 * time.Hour)},
+		{Digest: "sha256:5", Tags: []string{"1.4.0", "latest"}, PushedAt: now.Add(-1 * time.Hour)},
+		{Digest: "sha256:3", Tags: []string{"1.2.0"}, PushedAt: now.Add(-3 * time.Hour)},
+		{Digest: "sha256:2", PushedAt: now.Add(-4 * time.Hour)},
+		{Digest: "sha256:4", Tags: []string{"1.3.0"}, PushedAt: now.Add(-2 * time.Hour)},
+	}
+
+	digests := func(images []Image) string {
+		d := []string{}
+		for _, image := range images {
+			d = append(d, image.Digest)
+		}
+		return strings.Join(d, ",")
+	}
+
+	tests := []struct {
+		keep      int
+		protected Protected
+		want      string
+	}{
+		{2, nil, "sha256:3,sha256:2,sha256:1"},
+		{10, nil, ""},
+		{0, nil, "sha256:5,sha256:4,sha256:3,sha256:2,sha256:1"},
+		// deployed 1.0.0 by tag and untagged image by digest are kept on top of the newest two
+		{2, Protected{"1.0.0": true, "sha256:2": true}, "sha256:3"},
+	}
+
+	for _, test := range tests {
+		if got := digests(Select(images, test.keep, test.protected)); got != test.want {
+			t.Errorf("Select(%d, %v) = %s, want %s", test.keep, test.protected, got, test.want)
+		}
+	}
+
+	if images[0].Digest != "sha256:1" {
+		t.Error("images were sorted in place")
+	}
+}
@@ -0,0 +1,109 @@
+package rightsize
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Size is cpu units and memory in MiB a size reserves
+type Size struct {
+	Name   string
+	CPU    int64
+	Memory int64
+}
+
+// Usage is cpu units and memory in MiB a task uses
+type Usage struct {
+	CPU    float64
+	Memory float64
+}
+
+// Percentile returns p-th percentile of values using nearest rank. values are not modified
+func Percentile(values []float64, p float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, errors.New("no values")
+	}
+	if p <= 0 || p > 100 {
+		return 0, fmt.Errorf("percentile %v out of range", p)
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[rank-1], nil
+}
+
+// UsageOf converts utilization percentages of reserved cpu and memory to absolute usage
+func UsageOf(reserved Size, cpuPercent, memoryPercent float64) Usage {
+	return Usage{
+		CPU:    float64(reserved.CPU) * cpuPercent / 100,
+		Memory: float64(reserved.Memory) * memoryPercent / 100,
+	}
+}
+
+// Required adds headroom percent to usage
+func Required(usage Usage, headroom float64) Usage {
+	return Usage{
+		CPU:    usage.CPU * (1 + headroom/100),
+		Memory: usage.Memory * (1 + headroom/100),
+	}
+}
+
+// Recommend returns the smallest size that fits usage with headroom. sizes are compared by memory first since
+// memory is a hard limit and cpu is only a share
+func Recommend(sizes []Size, usage Usage, headroom float64) (Size, error) {
+	sorted := append([]Size{}, sizes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Memory != sorted[j].Memory {
+			return sorted[i].Memory < sorted[j].Memory
+		}
+		if sorted[i].CPU != sorted[j].CPU {
+			return sorted[i].CPU < sorted[j].CPU
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	required := Required(usage, headroom)
+	for _, s := range sorted {
+		if float64(s.CPU) >= required.CPU && float64(s.Memory) >= required.Memory {
+			return s, nil
+		}
+	}
+
+	return Size{}, fmt.Errorf("no size fits cpu %.0f, memory %.0f", required.CPU, required.Memory)
+}
+
+// Match returns name of the size reserving exactly cpu and memory or empty string
+func Match(sizes []Size, reserved Size) string {
+	for _, s := range sizes {
+		if s.CPU == reserved.CPU && s.Memory == reserved.Memory {
+			return s.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,97 @@
+package rightsize
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import "testing"
+
+var testSizes = []Size{
+	{"xsmall", 64, 128},
+	{"small", 128, 256},
+	{"medium", 256, 512},
+	{"large", 512, 1024},
+	{"xlarge", 1024, 2048},
+}
+
+func TestPercentile(t *testing.T) {
+
+	values := []float64{5, 1, 4, 2, 3, 6, 7, 8, 9, 10}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{50, 5},
+		{95, 10},
+		{90, 9},
+		{10, 1},
+		{100, 10},
+	}
+
+	for _, test := range tests {
+		got, err := Percentile(values, test.p)
+		if err != nil || got != test.want {
+			t.Errorf("Percentile(%v) = %v, %v; want %v", test.p, got, err, test.want)
+		}
+	}
+
+	if values[0] != 5 {
+		t.Error("values were sorted in place")
+	}
+
+	if _, err := Percentile(nil, 95); err == nil {
+		t.Error("expected error for no values")
+	}
+}
+
+func TestRecommend(t *testing.T) {
+
+	reserved := Size{"xlarge", 1024, 2048}
+
+	// 10% of cpu and 20% of memory with 30% headroom: cpu 133, memory 532
+	usage := UsageOf(reserved, 10, 20)
+	s, err := Recommend(testSizes, usage, 30)
+	if err != nil || s.Name != "large" {
+		t.Errorf("Recommend() = %v, %v", s, err)
+	}
+
+	// tiny usage fits the smallest size
+	s, err = Recommend(testSizes, UsageOf(reserved, 1, 1), 30)
+	if err != nil || s.Name != "xsmall" {
+		t.Errorf("Recommend() = %v, %v", s, err)
+	}
+
+	// more than the largest size
+	if _, err := Recommend(testSizes, UsageOf(reserved, 90, 95), 30); err == nil {
+		t.Error("expected no size to fit")
+	}
+}
+
+func TestMatch(t *testing.T) {
+
+	if name := Match(testSizes, Size{CPU: 256, Memory: 512}); name != "medium" {
+		t.Errorf("Match() = %s", name)
+	}
+	if name := Match(testSizes, Size{CPU: 300, Memory: 512}); name != "" {
+		t.Errorf("Match() = %s", name)
+	}
+}
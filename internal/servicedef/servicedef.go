@@ -0,0 +1,241 @@
+package servicedef
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// BaseFile is the name of the base definition in the service directory. overlays are named after the environment
+const BaseFile = "base.yaml"
+
+// Definition describes an ecs service. the same struct is used for the base and the overlays so that
+// overlays can only set fields the base knows about
+type Definition struct {
+	Service           string            `yaml:"service,omitempty"`
+	Cluster           string            `yaml:"cluster,omitempty"`
+	Image             string            `yaml:"image,omitempty"`
+	Size              string            `yaml:"size,omitempty"`
+	Port              int64             `yaml:"port,omitempty"`
+	DesiredCount      *int64            `yaml:"desiredCount,omitempty"`
+	MinHealthyPercent *int64            `yaml:"minHealthyPercent,omitempty"`
+	MaxPercent        *int64            `yaml:"maxPercent,omitempty"`
+	Env               map[string]string `yaml:"env,omitempty"`
+}
+
+// TemplateData is available to definition files as go template variables
+type TemplateData struct {
+	Service string
+	Env     string
+	Vars    map[string]string
+}
+
+// Fields returns the yaml names of fields a definition can set
+func Fields() []string {
+	fields := []string{}
+	t := reflect.TypeOf(Definition{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Load reads base definition of service in dir and merges the overlay of env on top of it.
+// if env is empty only the base is loaded
+func Load(dir, service, env string, vars map[string]string) (Definition, error) {
+	data := TemplateData{Service: service, Env: env, Vars: vars}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+
+	d, err := ReadFile(filepath.Join(dir, service, BaseFile), data)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	if len(env) > 0 {
+		overlay, err := ReadFile(filepath.Join(dir, service, env+".yaml"), data)
+		if err != nil {
+			return Definition{}, err
+		}
+		d = Merge(d, overlay)
+	}
+
+	if len(d.Service) == 0 {
+		d.Service = service
+	}
+
+	return d, d.Validate()
+}
+
+// ReadFile renders definition file as go template and parses it
+func ReadFile(path string, data TemplateData) (Definition, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Definition{}, err
+	}
+
+	d, err := Parse(filepath.Base(path), content, data)
+	if err != nil {
+		return Definition{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return d, nil
+}
+
+// Parse renders content as go template and parses the result. unknown fields are rejected
+func Parse(name string, content []byte, data TemplateData) (Definition, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(template.FuncMap{
+		"env": os.Getenv,
+	}).Parse(string(content))
+	if err != nil {
+		return Definition{}, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return Definition{}, err
+	}
+
+	if err := checkFields(buf.Bytes()); err != nil {
+		return Definition{}, err
+	}
+
+	d := Definition{}
+	if err := yaml.UnmarshalStrict(buf.Bytes(), &d); err != nil {
+		return Definition{}, err
+	}
+
+	return d, nil
+}
+
+// checkFields gives a friendlier error than strict unmarshal when a field is misspelled
+func checkFields(content []byte) error {
+	m := map[string]interface{}{}
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return err
+	}
+
+	known := map[string]bool{}
+	for _, f := range Fields() {
+		known[f] = true
+	}
+
+	unknown := []string{}
+	for k := range m {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown fields %s. known fields are %s", strings.Join(unknown, ","), strings.Join(Fields(), ","))
+	}
+
+	return nil
+}
+
+// Merge returns base with fields set in overlay replaced. env is merged key by key
+func Merge(base, overlay Definition) Definition {
+	d := base
+
+	if len(overlay.Service) > 0 {
+		d.Service = overlay.Service
+	}
+	if len(overlay.Cluster) > 0 {
+		d.Cluster = overlay.Cluster
+	}
+	if len(overlay.Image) > 0 {
+		d.Image = overlay.Image
+	}
+	if len(overlay.Size) > 0 {
+		d.Size = overlay.Size
+	}
+	if overlay.Port > 0 {
+		d.Port = overlay.Port
+	}
+	if overlay.DesiredCount != nil {
+		d.DesiredCount = overlay.DesiredCount
+	}
+	if overlay.MinHealthyPercent != nil {
+		d.MinHealthyPercent = overlay.MinHealthyPercent
+	}
+	if overlay.MaxPercent != nil {
+		d.MaxPercent = overlay.MaxPercent
+	}
+
+	env := map[string]string{}
+	for k, v := range base.Env {
+		env[k] = v
+	}
+	for k, v := range overlay.Env {
+		env[k] = v
+	}
+	d.Env = env
+
+	return d
+}
+
+// Validate checks merged definition has everything needed to deploy
+func (d Definition) Validate() error {
+	missing := []string{}
+	if len(d.Cluster) == 0 {
+		missing = append(missing, "cluster")
+	}
+	if len(d.Image) == 0 {
+		missing = append(missing, "image")
+	}
+	if len(d.Size) == 0 {
+		missing = append(missing, "size")
+	}
+	if d.Port <= 0 {
+		missing = append(missing, "port")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("service definition of %s is missing %s", d.Service, strings.Join(missing, ","))
+	}
+
+	if d.DesiredCount != nil && *d.DesiredCount < 0 {
+		return errors.New("desiredCount must not be negative")
+	}
+
+	return nil
+}
+
+// Marshal returns yaml of definition
+func Marshal(d Definition) ([]byte, error) {
+	return yaml.Marshal(d)
+}
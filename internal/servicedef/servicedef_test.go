@@ -0,0 +1,124 @@
+package servicedef
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testBase = `
+image: 7onetella/foo-svc:{{ .Vars.version }}
+size: small
+port: 8080
+desiredCount: 1
+env:
+  NAME: {{ .Service }}
+  URLPREFIX: {{ .Service }}.{{ .Env }}.example.com/
+`
+
+const testOverlay = `
+cluster: {{ .Env }}-cluster
+size: large
+desiredCount: 3
+env:
+  LOG_LEVEL: warn
+`
+
+func TestLoad(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "servicedef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "foo-svc"), 0755)
+	ioutil.WriteFile(filepath.Join(dir, "foo-svc", BaseFile), []byte(testBase), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "foo-svc", "stage.yaml"), []byte(testOverlay), 0644)
+
+	d, err := Load(dir, "foo-svc", "stage", map[string]string{"version": "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d.Service != "foo-svc" || d.Cluster != "stage-cluster" || d.Size != "large" || d.Port != 8080 || *d.DesiredCount != 3 {
+		t.Errorf("Load() = %+v", d)
+	}
+	if d.Image != "7onetella/foo-svc:1.0.0" {
+		t.Errorf("Image = %s", d.Image)
+	}
+	if d.Env["NAME"] != "foo-svc" || d.Env["URLPREFIX"] != "foo-svc.stage.example.com/" || d.Env["LOG_LEVEL"] != "warn" {
+		t.Errorf("Env = %v", d.Env)
+	}
+
+	// base alone has no cluster
+	if _, err := Load(dir, "foo-svc", "", map[string]string{"version": "1.0.0"}); err == nil || !strings.Contains(err.Error(), "cluster") {
+		t.Errorf("expected missing cluster, got %v", err)
+	}
+
+	// template variable not provided
+	if _, err := Load(dir, "foo-svc", "stage", nil); err == nil {
+		t.Error("expected error for missing version variable")
+	}
+
+	// overlay of unknown environment
+	if _, err := Load(dir, "foo-svc", "prod", map[string]string{"version": "1.0.0"}); err == nil {
+		t.Error("expected error for missing overlay")
+	}
+}
+
+func TestParseUnknownFields(t *testing.T) {
+
+	_, err := Parse("stage.yaml", []byte("sizes: large\nreplicas: 2\n"), TemplateData{})
+	if err == nil || !strings.Contains(err.Error(), "unknown fields replicas,sizes") {
+		t.Errorf("Parse() error = %v", err)
+	}
+
+	_, err = Parse("stage.yaml", []byte("port: http\n"), TemplateData{})
+	if err == nil {
+		t.Error("expected error for invalid port")
+	}
+}
+
+func TestMerge(t *testing.T) {
+
+	one, zero := int64(1), int64(0)
+	base := Definition{Cluster: "dev", Size: "small", DesiredCount: &one, Env: map[string]string{"A": "1", "B": "2"}}
+	overlay := Definition{DesiredCount: &zero, Env: map[string]string{"B": "3"}}
+
+	d := Merge(base, overlay)
+
+	if d.Cluster != "dev" || d.Size != "small" || *d.DesiredCount != 0 {
+		t.Errorf("Merge() = %+v", d)
+	}
+	if d.Env["A"] != "1" || d.Env["B"] != "3" {
+		t.Errorf("Env = %v", d.Env)
+	}
+	if base.Env["B"] != "2" {
+		t.Error("base env was modified")
+	}
+}
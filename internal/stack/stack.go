@@ -0,0 +1,128 @@
+package stack
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Service is a member of stack. services are a list with names rather than a map keyed by name
+// since config keys are lowercased and ecs service names are case sensitive
+type Service struct {
+	Name          string   `mapstructure:"name"`
+	DependsOn     []string `mapstructure:"depends_on"`
+	DesiredCount  int64    `mapstructure:"desired_count"`
+	ConsulService string   `mapstructure:"consul_service"`
+}
+
+// Stack is a group of services started and stopped together in dependency order
+type Stack struct {
+	Cluster       string    `mapstructure:"cluster"`
+	ConsulAddress string    `mapstructure:"consul_address"`
+	Services      []Service `mapstructure:"services"`
+}
+
+// Member returns service of stack by name. zero service is returned when it is not in the stack
+func (s Stack) Member(name string) Service {
+	for _, svc := range s.Services {
+		if svc.Name == name {
+			return svc
+		}
+	}
+	return Service{}
+}
+
+// Tiers returns services in start order. services in a tier only depend on services in earlier tiers
+// and can be started concurrently. names in a tier are sorted
+func (s Stack) Tiers() ([][]string, error) {
+	if len(s.Services) == 0 {
+		return nil, fmt.Errorf("stack has no services")
+	}
+
+	remaining := map[string][]string{}
+	for _, svc := range s.Services {
+		if len(svc.Name) == 0 {
+			return nil, fmt.Errorf("stack has service without name")
+		}
+		if _, ok := remaining[svc.Name]; ok {
+			return nil, fmt.Errorf("%s is in the stack more than once", svc.Name)
+		}
+		remaining[svc.Name] = svc.DependsOn
+	}
+	for name, deps := range remaining {
+		for _, dep := range deps {
+			if _, ok := remaining[dep]; !ok {
+				return nil, fmt.Errorf("%s depends on %s which is not in the stack", name, dep)
+			}
+		}
+	}
+
+	started := map[string]bool{}
+	tiers := [][]string{}
+	for len(remaining) > 0 {
+		tier := []string{}
+		for name, deps := range remaining {
+			if all(deps, started) {
+				tier = append(tier, name)
+			}
+		}
+
+		if len(tier) == 0 {
+			names := []string{}
+			for name := range remaining {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("dependency cycle among %s", strings.Join(names, ","))
+		}
+
+		sort.Strings(tier)
+		for _, name := range tier {
+			started[name] = true
+			delete(remaining, name)
+		}
+		tiers = append(tiers, tier)
+	}
+
+	return tiers, nil
+}
+
+func all(names []string, set map[string]bool) bool {
+	for _, name := range names {
+		if !set[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Reverse returns tiers in stop order
+func Reverse(tiers [][]string) [][]string {
+	reversed := [][]string{}
+	for i := len(tiers) - 1; i >= 0; i-- {
+		reversed = append(reversed, tiers[i])
+	}
+	return reversed
+}
@@ -0,0 +1,80 @@
+package stack
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTiers(t *testing.T) {
+
+	s := Stack{Services: []Service{
+		{Name: "Web", DependsOn: []string{"api", "auth"}},
+		{Name: "api", DependsOn: []string{"db-proxy", "config"}},
+		{Name: "auth", DependsOn: []string{"config"}},
+		{Name: "db-proxy", DependsOn: []string{"config"}},
+		{Name: "config"},
+		{Name: "telemetry", DesiredCount: 2},
+	}}
+
+	tiers, err := s.Tiers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := fmt.Sprint(tiers); got != "[[config telemetry] [auth db-proxy] [api] [Web]]" {
+		t.Errorf("Tiers() = %s", got)
+	}
+
+	if got := fmt.Sprint(Reverse(tiers)); got != "[[Web] [api] [auth db-proxy] [config telemetry]]" {
+		t.Errorf("Reverse() = %s", got)
+	}
+
+	if s.Member("telemetry").DesiredCount != 2 || len(s.Member("web").Name) > 0 {
+		t.Error("Member() did not find service by its exact name")
+	}
+}
+
+func TestTiersInvalid(t *testing.T) {
+
+	tests := []struct {
+		services []Service
+		want     string
+	}{
+		{[]Service{{Name: "a", DependsOn: []string{"b"}}, {Name: "b", DependsOn: []string{"c"}}, {Name: "c", DependsOn: []string{"a"}}, {Name: "d"}}, "dependency cycle among a,b,c"},
+		{[]Service{{Name: "a", DependsOn: []string{"missing"}}}, "a depends on missing which is not in the stack"},
+		{[]Service{{Name: "Api"}, {Name: "web", DependsOn: []string{"api"}}}, "web depends on api which is not in the stack"},
+		{[]Service{{Name: "a"}, {Name: "a"}}, "a is in the stack more than once"},
+		{[]Service{{DependsOn: []string{"a"}}}, "stack has service without name"},
+		{[]Service{}, "stack has no services"},
+	}
+
+	for _, test := range tests {
+		_, err := Stack{Services: test.services}.Tiers()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("Tiers() error = %v, want %s", err, test.want)
+		}
+	}
+}
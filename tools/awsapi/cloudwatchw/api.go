@@ -0,0 +1,94 @@
+package cloudwatchw
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+)
+
+const awsTimeoutDefault = 10
+
+// maxDatapoints is the most datapoints get metric statistics returns in one call
+const maxDatapoints = 1440
+
+func newCloudWatch() (*cloudwatch.CloudWatch, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Region = endpoints.UsEast1RegionID
+
+	return cloudwatch.New(cfg), nil
+}
+
+func newContextWithTimeout(timeout int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// Period returns the smallest period in seconds that keeps the window from start to end within one call.
+// cloudwatch only returns datapoints older than 15 days for periods that are multiple of 300
+// and older than 63 days for periods that are multiple of 3600
+func Period(start, end time.Time) int64 {
+	granularity := int64(60)
+	switch age := time.Since(start); {
+	case age > 63*24*time.Hour:
+		granularity = 3600
+	case age > 15*24*time.Hour:
+		granularity = 300
+	}
+
+	seconds := int64(end.Sub(start).Seconds())
+	perDatapoint := (seconds + maxDatapoints - 1) / maxDatapoints
+	period := (perDatapoint + granularity - 1) / granularity * granularity
+	if period < granularity {
+		period = granularity
+	}
+	return period
+}
+
+// GetMetricStatistics gets datapoints of metric sorted by time
+func GetMetricStatistics(namespace, metric string, dimensions map[string]string, start, end time.Time, period int64, statistics ...cloudwatch.Statistic) ([]cloudwatch.Datapoint, error) {
+	svc, err := newCloudWatch()
+	if err != nil {
+		return nil, err
+	}
+
+	dims := []cloudwatch.Dimension{}
+	for name, value := range dimensions {
+		dims = append(dims, cloudwatch.Dimension{
+			Name:  aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+
+	req := svc.GetMetricStatisticsRequest(&cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metric),
+		Dimensions: dims,
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int64(period),
+		Statistics: statistics,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	result, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	datapoints := result.Datapoints
+	sort.Slice(datapoints, func(i, j int) bool {
+		return datapoints[i].Timestamp.Before(*datapoints[j].Timestamp)
+	})
+
+	return datapoints, nil
+}
@@ -0,0 +1,37 @@
+package cloudwatchw
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriod(t *testing.T) {
+
+	day := 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		age    time.Duration
+		window time.Duration
+		period int64
+	}{
+		{"an hour", time.Hour, time.Hour, 60},
+		{"one day", day, day, 60},
+		{"one day and a minute", day + time.Minute, day + time.Minute, 120},
+		{"14 days", 14 * day, 14 * day, 840},
+		{"16 days", 16 * day, 16 * day, 1200},
+		{"one day 16 days ago", 16 * day, day, 300},
+		{"30 days", 30 * day, 30 * day, 1800},
+		{"64 days", 64 * day, 64 * day, 7200},
+		{"one day 64 days ago", 64 * day, day, 3600},
+	}
+
+	now := time.Now()
+	for _, test := range tests {
+		start := now.Add(-test.age)
+		period := Period(start, start.Add(test.window))
+		if period != test.period {
+			t.Errorf("%s: Period() = %d, expected %d", test.name, period, test.period)
+		}
+	}
+}
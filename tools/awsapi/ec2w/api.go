@@ -104,6 +104,34 @@ func DescribeInstanceByTagAndValue(tagName string, values ...string) (*ec2.Descr
 	return req.Send(ctx)
 }
 
+// DescribeInstance describes instance by id
+func DescribeInstance(instanceID string) (*ec2.Instance, error) {
+	svc, err := newEC2()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DescribeInstancesRequest(&ec2.DescribeInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	resp, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range resp.Reservations {
+		for _, instance := range reservation.Instances {
+			return &instance, nil
+		}
+	}
+
+	return nil, fmt.Errorf("can not find instance %s", instanceID)
+}
+
 // DescribeInstanceByNameTag describes instance by name tag
 func DescribeInstanceByNameTag(name string) (*ec2.DescribeInstancesOutput, error) {
 	return DescribeInstanceByTagAndValue("Name", name)
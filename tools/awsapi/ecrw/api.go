@@ -0,0 +1,224 @@
+package ecrw
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+)
+
+const awsTimeoutDefault = 3
+
+func newECR() (*ecr.ECR, error) {
+	return newECRInRegion("")
+}
+
+// newECRInRegion returns ecr client for region of the registry. empty region is the default region
+func newECRInRegion(region string) (*ecr.ECR, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Region = endpoints.UsEast1RegionID
+	if len(region) > 0 {
+		cfg.Region = region
+	}
+
+	return ecr.New(cfg), nil
+}
+
+func newContextWithTimeout(timeout int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// GetAuthorizationToken gets docker registry authorization token for given registry ids in region
+func GetAuthorizationToken(region string, registryIDs ...string) (*ecr.GetAuthorizationTokenOutput, error) {
+	svc, err := newECRInRegion(region)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &ecr.GetAuthorizationTokenInput{}
+	if len(registryIDs) > 0 {
+		input.RegistryIds = registryIDs
+	}
+
+	req := svc.GetAuthorizationTokenRequest(input)
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// GetRegistryCredentials returns docker registry username and password for given registry id. tokens are issued per region
+func GetRegistryCredentials(registryID, region string) (string, string, error) {
+	result, err := GetAuthorizationToken(region, registryID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if len(result.AuthorizationData) == 0 {
+		return "", "", errors.New("no authorization data returned")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(*result.AuthorizationData[0].AuthorizationToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	// token is base64 encoded username:password
+	tokens := strings.SplitN(string(data), ":", 2)
+	if len(tokens) != 2 {
+		return "", "", errors.New("malformed authorization token")
+	}
+
+	return tokens[0], tokens[1], nil
+}
+
+// DescribeRepositories describes all repositories of the default registry
+func DescribeRepositories() ([]ecr.Repository, error) {
+	svc, err := newECR()
+	if err != nil {
+		return nil, err
+	}
+
+	repositories := []ecr.Repository{}
+	var nextToken *string
+	for {
+		req := svc.DescribeRepositoriesRequest(&ecr.DescribeRepositoriesInput{
+			NextToken: nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		repositories = append(repositories, result.Repositories...)
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return repositories, nil
+}
+
+// DescribeImages describes all images of repository
+func DescribeImages(repository string) ([]ecr.ImageDetail, error) {
+	svc, err := newECR()
+	if err != nil {
+		return nil, err
+	}
+
+	images := []ecr.ImageDetail{}
+	var nextToken *string
+	for {
+		req := svc.DescribeImagesRequest(&ecr.DescribeImagesInput{
+			RepositoryName: aws.String(repository),
+			NextToken:      nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		images = append(images, result.ImageDetails...)
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return images, nil
+}
+
+// ImageExists checks to see if image with tag or digest exists in repository of registry in region. empty registry id is the default registry
+func ImageExists(registryID, region, repository, tag, digest string) (bool, error) {
+	svc, err := newECRInRegion(region)
+	if err != nil {
+		return false, err
+	}
+
+	id := ecr.ImageIdentifier{}
+	if len(digest) > 0 {
+		id.ImageDigest = aws.String(digest)
+	} else {
+		id.ImageTag = aws.String(tag)
+	}
+
+	input := &ecr.DescribeImagesInput{
+		RepositoryName: aws.String(repository),
+		ImageIds:       []ecr.ImageIdentifier{id},
+	}
+	if len(registryID) > 0 {
+		input.RegistryId = aws.String(registryID)
+	}
+
+	req := svc.DescribeImagesRequest(input)
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	_, err = req.Send(ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), ecr.ErrCodeImageNotFoundException) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// BatchDeleteImages deletes images by digest. images are deleted 100 at a time, the most batch delete image accepts
+func BatchDeleteImages(repository string, digests ...string) ([]ecr.ImageFailure, error) {
+	svc, err := newECR()
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []ecr.ImageFailure{}
+	for start := 0; start < len(digests); start += 100 {
+		end := start + 100
+		if end > len(digests) {
+			end = len(digests)
+		}
+
+		ids := []ecr.ImageIdentifier{}
+		for _, digest := range digests[start:end] {
+			ids = append(ids, ecr.ImageIdentifier{ImageDigest: aws.String(digest)})
+		}
+
+		req := svc.BatchDeleteImageRequest(&ecr.BatchDeleteImageInput{
+			RepositoryName: aws.String(repository),
+			ImageIds:       ids,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return failures, err
+		}
+
+		failures = append(failures, result.Failures...)
+	}
+
+	return failures, nil
+}
@@ -2,6 +2,8 @@ package ecsw
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
 	"strings"
 	"time"
 
@@ -133,19 +135,115 @@ func ListServices(cluster string) (*ecs.ListServicesOutput, error) {
 	return req.Send(ctx)
 }
 
+// ListAllServices lists names of all services in cluster following pagination
+func ListAllServices(cluster string) ([]string, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	services := []string{}
+	var nextToken *string
+
+	for {
+		req := svc.ListServicesRequest(&ecs.ListServicesInput{
+			Cluster:    aws.String(cluster),
+			MaxResults: aws.Int64(100),
+			NextToken:  nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return services, err
+		}
+
+		for _, arn := range result.ServiceArns {
+			i := strings.LastIndex(arn, "/")
+			services = append(services, arn[i+1:])
+		}
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return services, nil
+}
+
+// GetServicesByCluster gets names of services for every cluster
+func GetServicesByCluster() (map[string][]string, error) {
+	clusters := map[string][]string{}
+
+	result, err := ListClusters()
+	if err != nil {
+		return clusters, err
+	}
+
+	for _, arn := range result.ClusterArns {
+		i := strings.LastIndex(arn, "/")
+		cluster := arn[i+1:]
+
+		services, err := ListAllServices(cluster)
+		if err != nil {
+			return clusters, err
+		}
+		clusters[cluster] = services
+	}
+
+	return clusters, nil
+}
+
+// ServiceOptions optional service settings. nil and false values leave aws defaults in place
+type ServiceOptions struct {
+	MinimumHealthyPercent  *int64
+	MaximumPercent         *int64
+	HealthCheckGracePeriod *int64
+	ForceNewDeployment     bool
+	PlacementConstraints   []ecs.PlacementConstraint
+	PlacementStrategy      []ecs.PlacementStrategy
+	ServiceRegistries      []ecs.ServiceRegistry
+}
+
+func (o ServiceOptions) deploymentConfiguration() *ecs.DeploymentConfiguration {
+	if o.MinimumHealthyPercent == nil && o.MaximumPercent == nil {
+		return nil
+	}
+
+	return &ecs.DeploymentConfiguration{
+		MinimumHealthyPercent: o.MinimumHealthyPercent,
+		MaximumPercent:        o.MaximumPercent,
+	}
+}
+
 // UpdateService updates ecs service
 func UpdateService(cluster, service, taskdef string, desiredCount int64) (*ecs.UpdateServiceOutput, error) {
+	return UpdateServiceWithOptions(cluster, service, taskdef, desiredCount, ServiceOptions{})
+}
+
+// UpdateServiceWithOptions updates ecs service with deployment options
+func UpdateServiceWithOptions(cluster, service, taskdef string, desiredCount int64, opts ServiceOptions) (*ecs.UpdateServiceOutput, error) {
 	svc, err := newECS()
 	if err != nil {
 		return nil, err
 	}
 
-	req := svc.UpdateServiceRequest(&ecs.UpdateServiceInput{
-		Cluster:        aws.String(cluster),
-		Service:        aws.String(service),
-		TaskDefinition: aws.String(taskdef),
-		DesiredCount:   aws.Int64(desiredCount),
-	})
+	input := &ecs.UpdateServiceInput{
+		Cluster:                       aws.String(cluster),
+		Service:                       aws.String(service),
+		TaskDefinition:                aws.String(taskdef),
+		DesiredCount:                  aws.Int64(desiredCount),
+		DeploymentConfiguration:       opts.deploymentConfiguration(),
+		HealthCheckGracePeriodSeconds: opts.HealthCheckGracePeriod,
+	}
+
+	if opts.ForceNewDeployment {
+		input.ForceNewDeployment = aws.Bool(true)
+	}
+
+	req := svc.UpdateServiceRequest(input)
 
 	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
 	defer cancel()
@@ -154,19 +252,26 @@ func UpdateService(cluster, service, taskdef string, desiredCount int64) (*ecs.U
 }
 
 // CreateService creates ecs service
-func CreateService(cluster, service, taskdef string, desiredCount int64) (*ecs.CreateServiceOutput, error) {
+func CreateService(cluster, service, taskdef string, desiredCount int64, opts ServiceOptions) (*ecs.CreateServiceOutput, error) {
 	svc, err := newECS()
 	if err != nil {
 		return nil, err
 	}
 
-	req := svc.CreateServiceRequest(&ecs.CreateServiceInput{
-		Cluster:        aws.String(cluster),
-		ServiceName:    aws.String(service),
-		TaskDefinition: aws.String(taskdef),
-		DesiredCount:   aws.Int64(desiredCount),
-		LaunchType:     ecs.LaunchTypeEc2,
-	})
+	input := &ecs.CreateServiceInput{
+		Cluster:                       aws.String(cluster),
+		ServiceName:                   aws.String(service),
+		TaskDefinition:                aws.String(taskdef),
+		DesiredCount:                  aws.Int64(desiredCount),
+		LaunchType:                    ecs.LaunchTypeEc2,
+		DeploymentConfiguration:       opts.deploymentConfiguration(),
+		HealthCheckGracePeriodSeconds: opts.HealthCheckGracePeriod,
+		PlacementConstraints:          opts.PlacementConstraints,
+		PlacementStrategy:             opts.PlacementStrategy,
+		ServiceRegistries:             opts.ServiceRegistries,
+	}
+
+	req := svc.CreateServiceRequest(input)
 
 	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
 	defer cancel()
@@ -381,22 +486,36 @@ func DescribeTaskDefinition(taskdef string) (*ecs.DescribeTaskDefinitionOutput,
 	return req.Send(ctx)
 }
 
-// RegisterTaskDefinition registers task definition
+// RegisterTaskDefinition registers task definition. when td is a described revision, its tags are carried over to the new revision
 func RegisterTaskDefinition(td *ecs.TaskDefinition) (*ecs.RegisterTaskDefinitionOutput, error) {
 	svc, err := newECS()
 	if err != nil {
 		return nil, err
 	}
 
+	var tags []ecs.Tag
+	if td.TaskDefinitionArn != nil {
+		tags, err = taskDefinitionTags(svc, *td.TaskDefinitionArn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// every field accepted by register is copied so that re-registering keeps roles and proxy
 	req := svc.RegisterTaskDefinitionRequest(&ecs.RegisterTaskDefinitionInput{
 		Family:                  td.Family,
+		TaskRoleArn:             td.TaskRoleArn,
+		ExecutionRoleArn:        td.ExecutionRoleArn,
 		ContainerDefinitions:    td.ContainerDefinitions,
 		IpcMode:                 td.IpcMode,
+		Cpu:                     td.Cpu,
 		Memory:                  td.Memory,
 		NetworkMode:             td.NetworkMode,
 		PidMode:                 td.PidMode,
 		PlacementConstraints:    td.PlacementConstraints,
+		ProxyConfiguration:      td.ProxyConfiguration,
 		RequiresCompatibilities: td.RequiresCompatibilities,
+		Tags:                    tags,
 		Volumes:                 td.Volumes,
 	})
 
@@ -405,3 +524,197 @@ func RegisterTaskDefinition(td *ecs.TaskDefinition) (*ecs.RegisterTaskDefinition
 
 	return req.Send(ctx)
 }
+
+// taskDefinitionTags returns tags of task definition. tags are only returned by describe when asked for
+func taskDefinitionTags(svc *ecs.ECS, taskdef string) ([]ecs.Tag, error) {
+	req := svc.DescribeTaskDefinitionRequest(&ecs.DescribeTaskDefinitionInput{
+		TaskDefinition: aws.String(taskdef),
+		Include:        []ecs.TaskDefinitionField{ecs.TaskDefinitionFieldTags},
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	result, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Tags, nil
+}
+
+// ListTasks lists arns of running tasks of service
+func ListTasks(cluster, service string) ([]string, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := []string{}
+	var nextToken *string
+
+	for {
+		req := svc.ListTasksRequest(&ecs.ListTasksInput{
+			Cluster:       aws.String(cluster),
+			ServiceName:   aws.String(service),
+			DesiredStatus: ecs.DesiredStatusRunning,
+			NextToken:     nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return tasks, err
+		}
+
+		tasks = append(tasks, result.TaskArns...)
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return tasks, nil
+}
+
+// DescribeTasks describes ecs tasks
+func DescribeTasks(cluster string, tasks ...string) (*ecs.DescribeTasksOutput, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DescribeTasksRequest(&ecs.DescribeTasksInput{
+		Cluster: aws.String(cluster),
+		Tasks:   tasks,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// RunTask runs one-off task of task definition with container overrides
+func RunTask(cluster, taskdef, startedBy string, overrides []ecs.ContainerOverride) (*ecs.RunTaskOutput, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.RunTaskRequest(&ecs.RunTaskInput{
+		Cluster:        aws.String(cluster),
+		TaskDefinition: aws.String(taskdef),
+		Count:          aws.Int64(1),
+		StartedBy:      aws.String(startedBy),
+		Overrides: &ecs.TaskOverride{
+			ContainerOverrides: overrides,
+		},
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// StopTask stops ecs task
+func StopTask(cluster, task, reason string) (*ecs.StopTaskOutput, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.StopTaskRequest(&ecs.StopTaskInput{
+		Cluster: aws.String(cluster),
+		Task:    aws.String(task),
+		Reason:  aws.String(reason),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// ListContainerInstances lists arns of active container instances in cluster
+func ListContainerInstances(cluster string) ([]string, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	instances := []string{}
+	var nextToken *string
+
+	for {
+		req := svc.ListContainerInstancesRequest(&ecs.ListContainerInstancesInput{
+			Cluster:   aws.String(cluster),
+			Status:    ecs.ContainerInstanceStatusActive,
+			NextToken: nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return instances, err
+		}
+
+		instances = append(instances, result.ContainerInstanceArns...)
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return instances, nil
+}
+
+// DescribeContainerInstances describes container instances
+func DescribeContainerInstances(cluster string, instances ...string) (*ecs.DescribeContainerInstancesOutput, error) {
+	svc, err := newECS()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DescribeContainerInstancesRequest(&ecs.DescribeContainerInstancesInput{
+		Cluster:            aws.String(cluster),
+		ContainerInstances: instances,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// ReadTaskDefinitionFile reads task definition from json file in TaskDefinitionJSON format.
+// output of describe-task-definition that wraps the task definition in taskDefinition is accepted as well
+func ReadTaskDefinitionFile(path string) (*ecs.TaskDefinition, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapper := struct {
+		TaskDefinition *json.RawMessage `json:"taskDefinition"`
+	}{}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.TaskDefinition != nil {
+		data = *wrapper.TaskDefinition
+	}
+
+	// field names of TaskDefinitionJSON match sdk fields case insensitively
+	td := &ecs.TaskDefinition{}
+	if err := json.Unmarshal(data, td); err != nil {
+		return nil, err
+	}
+
+	return td, nil
+}
@@ -0,0 +1,207 @@
+package eventsw
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchevents"
+)
+
+const awsTimeoutDefault = 3
+
+func newEvents() (*cloudwatchevents.CloudWatchEvents, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Region = endpoints.UsEast1RegionID
+
+	return cloudwatchevents.New(cfg), nil
+}
+
+func newContextWithTimeout(timeout int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// PutScheduledRule creates or updates rule that is triggered on schedule
+func PutScheduledRule(name, schedule, description string) (*cloudwatchevents.PutRuleOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.PutRuleRequest(&cloudwatchevents.PutRuleInput{
+		Name:               aws.String(name),
+		ScheduleExpression: aws.String(schedule),
+		Description:        aws.String(description),
+		State:              cloudwatchevents.RuleStateEnabled,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// PutTargets creates or updates targets of rule
+func PutTargets(rule string, targets ...cloudwatchevents.Target) (*cloudwatchevents.PutTargetsOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.PutTargetsRequest(&cloudwatchevents.PutTargetsInput{
+		Rule:    aws.String(rule),
+		Targets: targets,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// DescribeRule describes rule
+func DescribeRule(name string) (*cloudwatchevents.DescribeRuleOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DescribeRuleRequest(&cloudwatchevents.DescribeRuleInput{
+		Name: aws.String(name),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// ListRules lists all rules following pagination
+func ListRules() ([]cloudwatchevents.Rule, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []cloudwatchevents.Rule{}
+	var nextToken *string
+
+	for {
+		req := svc.ListRulesRequest(&cloudwatchevents.ListRulesInput{
+			NextToken: nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return rules, err
+		}
+
+		rules = append(rules, result.Rules...)
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return rules, nil
+}
+
+// ListTargetsByRule lists targets of rule
+func ListTargetsByRule(rule string) ([]cloudwatchevents.Target, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.ListTargetsByRuleRequest(&cloudwatchevents.ListTargetsByRuleInput{
+		Rule: aws.String(rule),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	result, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Targets, nil
+}
+
+// EnableRule enables rule
+func EnableRule(name string) (*cloudwatchevents.EnableRuleOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.EnableRuleRequest(&cloudwatchevents.EnableRuleInput{
+		Name: aws.String(name),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// DisableRule disables rule
+func DisableRule(name string) (*cloudwatchevents.DisableRuleOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DisableRuleRequest(&cloudwatchevents.DisableRuleInput{
+		Name: aws.String(name),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// RemoveTargets removes targets from rule
+func RemoveTargets(rule string, ids ...string) (*cloudwatchevents.RemoveTargetsOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.RemoveTargetsRequest(&cloudwatchevents.RemoveTargetsInput{
+		Rule: aws.String(rule),
+		Ids:  ids,
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// DeleteRule deletes rule. targets must be removed first
+func DeleteRule(name string) (*cloudwatchevents.DeleteRuleOutput, error) {
+	svc, err := newEvents()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DeleteRuleRequest(&cloudwatchevents.DeleteRuleInput{
+		Name: aws.String(name),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
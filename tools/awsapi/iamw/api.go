@@ -0,0 +1,112 @@
+package iamw
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+)
+
+const awsTimeoutDefault = 3
+
+func newIAM() (*iam.IAM, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Region = endpoints.UsEast1RegionID
+
+	return iam.New(cfg), nil
+}
+
+func newContextWithTimeout(timeout int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// GetRoleArn gets arn of role by name. empty arn is returned if role does not exist
+func GetRoleArn(name string) (string, error) {
+	svc, err := newIAM()
+	if err != nil {
+		return "", err
+	}
+
+	req := svc.GetRoleRequest(&iam.GetRoleInput{
+		RoleName: aws.String(name),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	result, err := req.Send(ctx)
+	if err != nil {
+		if strings.Contains(err.Error(), iam.ErrCodeNoSuchEntityException) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return *result.Role.Arn, nil
+}
+
+// CreateServiceRole creates role assumable by aws service such as events.amazonaws.com and attaches managed policies to it
+func CreateServiceRole(name, service, description string, policyArns ...string) (string, error) {
+	svc, err := newIAM()
+	if err != nil {
+		return "", err
+	}
+
+	assumeRolePolicy := `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Principal":{"Service":"` + service + `"},"Action":"sts:AssumeRole"}]}`
+
+	req := svc.CreateRoleRequest(&iam.CreateRoleInput{
+		RoleName:                 aws.String(name),
+		AssumeRolePolicyDocument: aws.String(assumeRolePolicy),
+		Description:              aws.String(description),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	result, err := req.Send(ctx)
+	cancel()
+	if err != nil {
+		return "", err
+	}
+
+	for _, policyArn := range policyArns {
+		req := svc.AttachRolePolicyRequest(&iam.AttachRolePolicyInput{
+			RoleName:  aws.String(name),
+			PolicyArn: aws.String(policyArn),
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		_, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return *result.Role.Arn, nil
+}
+
+// PutRolePolicy creates or replaces inline policy of role
+func PutRolePolicy(role, name, document string) (*iam.PutRolePolicyOutput, error) {
+	svc, err := newIAM()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.PutRolePolicyRequest(&iam.PutRolePolicyInput{
+		RoleName:       aws.String(role),
+		PolicyName:     aws.String(name),
+		PolicyDocument: aws.String(document),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
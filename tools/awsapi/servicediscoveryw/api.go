@@ -0,0 +1,182 @@
+package servicediscoveryw
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/endpoints"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/service/servicediscovery"
+)
+
+const awsTimeoutDefault = 3
+
+func newServiceDiscovery() (*servicediscovery.ServiceDiscovery, error) {
+	cfg, err := external.LoadDefaultAWSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.Region = endpoints.UsEast1RegionID
+
+	return servicediscovery.New(cfg), nil
+}
+
+func newContextWithTimeout(timeout int64) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
+}
+
+// GetNamespaceIDByName gets cloud map namespace id by name. e.g. internal.local
+func GetNamespaceIDByName(name string) (string, error) {
+	svc, err := newServiceDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	var nextToken *string
+	for {
+		req := svc.ListNamespacesRequest(&servicediscovery.ListNamespacesInput{
+			NextToken: nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return "", err
+		}
+
+		for _, ns := range result.Namespaces {
+			if strings.TrimSuffix(*ns.Name, ".") == strings.TrimSuffix(name, ".") {
+				return *ns.Id, nil
+			}
+		}
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return "", fmt.Errorf("cloud map namespace %s not found", name)
+}
+
+// FindService finds cloud map service by name in namespace. nil is returned if not found
+func FindService(namespaceID, name string) (*servicediscovery.ServiceSummary, error) {
+	svc, err := newServiceDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	var nextToken *string
+	for {
+		req := svc.ListServicesRequest(&servicediscovery.ListServicesInput{
+			Filters: []servicediscovery.ServiceFilter{
+				servicediscovery.ServiceFilter{
+					Name:      servicediscovery.ServiceFilterNameNamespaceId,
+					Values:    []string{namespaceID},
+					Condition: servicediscovery.FilterConditionEq,
+				},
+			},
+			NextToken: nextToken,
+		})
+
+		ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+		result, err := req.Send(ctx)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+
+		for i, s := range result.Services {
+			if *s.Name == name {
+				return &result.Services[i], nil
+			}
+		}
+
+		if result.NextToken == nil {
+			break
+		}
+		nextToken = result.NextToken
+	}
+
+	return nil, nil
+}
+
+// CreatedByMorgan is the description of cloud map services created by morgan
+const CreatedByMorgan = "created by morgan"
+
+// CreateService creates cloud map service with dns records of given type. SRV for bridge networking with dynamic host ports, A for awsvpc
+func CreateService(namespaceID, name string, recordType servicediscovery.RecordType) (*servicediscovery.CreateServiceOutput, error) {
+	svc, err := newServiceDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.CreateServiceRequest(&servicediscovery.CreateServiceInput{
+		Name:             aws.String(name),
+		NamespaceId:      aws.String(namespaceID),
+		CreatorRequestId: aws.String(fmt.Sprintf("morgan-%s-%d", name, time.Now().UnixNano())),
+		Description:      aws.String(CreatedByMorgan),
+		DnsConfig: &servicediscovery.DnsConfig{
+			DnsRecords: []servicediscovery.DnsRecord{
+				servicediscovery.DnsRecord{
+					Type: recordType,
+					TTL:  aws.Int64(60),
+				},
+			},
+			RoutingPolicy: servicediscovery.RoutingPolicyMultivalue,
+		},
+		// ecs reports task health to cloud map
+		HealthCheckCustomConfig: &servicediscovery.HealthCheckCustomConfig{
+			FailureThreshold: aws.Int64(1),
+		},
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
+
+// GetService gets cloud map service by id
+func GetService(id string) (*servicediscovery.Service, error) {
+	svc, err := newServiceDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.GetServiceRequest(&servicediscovery.GetServiceInput{
+		Id: aws.String(id),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	result, err := req.Send(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Service, nil
+}
+
+// DeleteService deletes cloud map service. service can not be deleted while instances are registered
+func DeleteService(id string) (*servicediscovery.DeleteServiceOutput, error) {
+	svc, err := newServiceDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	req := svc.DeleteServiceRequest(&servicediscovery.DeleteServiceInput{
+		Id: aws.String(id),
+	})
+
+	ctx, cancel := newContextWithTimeout(awsTimeoutDefault)
+	defer cancel()
+
+	return req.Send(ctx)
+}
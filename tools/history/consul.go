@@ -0,0 +1,106 @@
+package history
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/consul/api"
+)
+
+// ConsulLedger stores records in consul kv under prefix/service/timestamp
+type ConsulLedger struct {
+	Address string
+	Prefix  string
+	Token   string
+}
+
+// NewConsulLedger initializes consul ledger
+func NewConsulLedger(address, prefix, token string) ConsulLedger {
+	return ConsulLedger{Address: address, Prefix: strings.Trim(prefix, "/"), Token: token}
+}
+
+func (l ConsulLedger) kv() (*api.KV, error) {
+	config := &api.Config{Address: l.Address, Scheme: "http", Token: l.Token}
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return client.KV(), nil
+}
+
+// Append stores record as json value. key sorts chronologically
+func (l ConsulLedger) Append(r Record) error {
+	kv, err := l.kv()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	key := fmt.Sprintf("%s/%s/%020d", l.Prefix, r.Service, r.Time.UnixNano())
+	_, err = kv.Put(&api.KVPair{Key: key, Value: data}, nil)
+
+	return err
+}
+
+// List lists records of service from consul kv
+func (l ConsulLedger) List(service string, limit int) ([]Record, error) {
+	records := []Record{}
+
+	kv, err := l.kv()
+	if err != nil {
+		return records, err
+	}
+
+	prefix := l.Prefix + "/"
+	if len(service) > 0 {
+		prefix += service + "/"
+	}
+
+	pairs, _, err := kv.List(prefix, nil)
+	if err != nil {
+		return records, err
+	}
+
+	for _, pair := range pairs {
+		r := Record{}
+		if err := json.Unmarshal(pair.Value, &r); err != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+
+	// keys of different services interleave so sort by time
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+
+	return latest(records, limit), nil
+}
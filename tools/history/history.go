@@ -0,0 +1,138 @@
+package history
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Outcomes of recorded actions
+const (
+	OutcomeSucceeded = "succeeded"
+	OutcomeFailed    = "failed"
+)
+
+// Record is a single entry in deployment history
+type Record struct {
+	Time              time.Time `json:"time"`
+	User              string    `json:"user"`
+	Action            string    `json:"action"`
+	Service           string    `json:"service"`
+	Cluster           string    `json:"cluster"`
+	OldTaskDefinition string    `json:"oldTaskDefinition,omitempty"`
+	NewTaskDefinition string    `json:"newTaskDefinition,omitempty"`
+	Images            []string  `json:"images,omitempty"`
+	OldDesiredCount   int64     `json:"oldDesiredCount"`
+	NewDesiredCount   int64     `json:"newDesiredCount"`
+	Outcome           string    `json:"outcome"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// Ledger stores deployment history
+type Ledger interface {
+	// Append appends record to the ledger
+	Append(r Record) error
+	// List lists records of service, most recent first. all services are listed if service is empty
+	List(service string, limit int) ([]Record, error)
+}
+
+// FileLedger stores records as json lines in local file
+type FileLedger struct {
+	Path string
+}
+
+// NewFileLedger initializes file ledger
+func NewFileLedger(path string) FileLedger {
+	return FileLedger{Path: path}
+}
+
+// Append appends record as a json line
+func (l FileLedger) Append(r Record) error {
+	if err := os.MkdirAll(filepath.Dir(l.Path), 0700); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// List reads records of service from the file
+func (l FileLedger) List(service string, limit int) ([]Record, error) {
+	records := []Record{}
+
+	f, err := os.Open(l.Path)
+	if os.IsNotExist(err) {
+		return records, nil
+	}
+	if err != nil {
+		return records, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		r := Record{}
+		// skip lines that are not records instead of failing the whole history
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if len(service) > 0 && r.Service != service {
+			continue
+		}
+		records = append(records, r)
+	}
+	if err := scanner.Err(); err != nil {
+		return records, err
+	}
+
+	return latest(records, limit), nil
+}
+
+// latest reverses records in chronological order and keeps at most limit records
+func latest(records []Record, limit int) []Record {
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+
+	return records
+}
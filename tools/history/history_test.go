@@ -0,0 +1,83 @@
+package history
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileLedger(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "morgan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ledger := NewFileLedger(filepath.Join(dir, "history", "deployments.jsonl"))
+
+	now := time.Now()
+	for i, service := range []string{"api", "web", "api", "api"} {
+		err := ledger.Append(Record{
+			Time:              now.Add(time.Duration(i) * time.Minute),
+			Action:            "update",
+			Service:           service,
+			NewTaskDefinition: service + ":" + string(rune('1'+i)),
+			Outcome:           OutcomeSucceeded,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err := ledger.List("api", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records but got %d", len(records))
+	}
+
+	// most recent first
+	if records[0].NewTaskDefinition != "api:4" || records[1].NewTaskDefinition != "api:3" {
+		t.Errorf("unexpected records %+v", records)
+	}
+
+	all, _ := ledger.List("", 0)
+	if len(all) != 4 {
+		t.Errorf("expected 4 records but got %d", len(all))
+	}
+}
+
+func TestFileLedgerMissingFile(t *testing.T) {
+
+	records, err := NewFileLedger("/nonexistent/morgan/history.jsonl").List("api", 10)
+	if err != nil || len(records) != 0 {
+		t.Errorf("List() = %v, %v", records, err)
+	}
+}
@@ -0,0 +1,277 @@
+package registry
+
+// MIT License
+
+// Copyright (c) 2019 7onetella
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/7onetella/morgan/internal/imageref"
+)
+
+// manifest media types accepted when resolving digests. manifest lists come first so that
+// multi-arch images resolve to the same digest docker pull would use
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+// Client talks to Docker Registry HTTP API v2
+type Client struct {
+	Username string
+	Password string
+	// Insecure uses http instead of https
+	Insecure bool
+	HTTP     *http.Client
+}
+
+// New initializes registry client with default timeout
+func New() *Client {
+	return &Client{
+		HTTP: &http.Client{Timeout: time.Second * 10},
+	}
+}
+
+// ResolveDigest resolves image tag to the immutable manifest digest
+func (c *Client) ResolveDigest(ref imageref.Reference) (string, error) {
+	if len(ref.Digest) > 0 {
+		return ref.Digest, nil
+	}
+
+	resp, err := c.manifest(http.MethodHead, ref)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if len(digest) == 0 {
+		return "", fmt.Errorf("registry did not return digest for %s", ref)
+	}
+
+	return digest, nil
+}
+
+// Exists checks to see if image manifest exists in the registry
+func (c *Client) Exists(ref imageref.Reference) (bool, error) {
+	resp, err := c.manifest(http.MethodHead, ref)
+	if err == errNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+
+	return true, nil
+}
+
+var errNotFound = errors.New("manifest not found")
+
+func (c *Client) manifest(method string, ref imageref.Reference) (*http.Response, error) {
+	reference := ref.TagOrDefault()
+	if len(ref.Digest) > 0 {
+		reference = ref.Digest
+	}
+
+	u := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", c.scheme(ref), apiHost(ref), ref.Repository(), reference)
+
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := c.do(req, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errNotFound
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, u)
+	}
+}
+
+// do sends request with basic auth. if registry challenges with bearer token auth, token is obtained and request is retried
+func (c *Client) do(req *http.Request, ref imageref.Reference) (*http.Response, error) {
+	if len(c.Username) > 0 {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return nil, fmt.Errorf("unauthorized to access %s", ref.Name())
+	}
+
+	token, err := c.token(challenge, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	retry, err := http.NewRequest(req.Method, req.URL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	retry.Header = req.Header
+	retry.Header.Set("Authorization", "Bearer "+token)
+
+	return c.HTTP.Do(retry)
+}
+
+func (c *Client) token(challenge string, ref imageref.Reference) (string, error) {
+	params := parseChallenge(challenge[len("bearer "):])
+
+	realm, ok := params["realm"]
+	if !ok {
+		return "", errors.New("bearer challenge without realm")
+	}
+
+	q := url.Values{}
+	if service, ok := params["service"]; ok {
+		q.Set("service", service)
+	}
+	scope, ok := params["scope"]
+	if !ok {
+		scope = "repository:" + ref.Repository() + ":pull"
+	}
+	q.Set("scope", scope)
+
+	req, err := http.NewRequest(http.MethodGet, realm+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if len(c.Username) > 0 {
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token request failed with %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	t := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(data, &t); err != nil {
+		return "", err
+	}
+
+	if len(t.Token) > 0 {
+		return t.Token, nil
+	}
+	return t.AccessToken, nil
+}
+
+// parseChallenge parses key="value" pairs of WWW-Authenticate header
+func parseChallenge(s string) map[string]string {
+	params := map[string]string{}
+	for len(s) > 0 {
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(s[:eq]))
+		s = s[eq+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end < 0 {
+				value, s = s[1:], ""
+			} else {
+				value, s = s[1:end+1], s[end+2:]
+			}
+		} else {
+			end := strings.Index(s, ",")
+			if end < 0 {
+				value, s = s, ""
+			} else {
+				value, s = s[:end], s[end:]
+			}
+		}
+		params[key] = value
+
+		s = strings.TrimLeft(s, ", ")
+	}
+	return params
+}
+
+func (c *Client) scheme(ref imageref.Reference) string {
+	if c.Insecure || isLocalhost(ref.Registry()) {
+		return "http"
+	}
+	return "https"
+}
+
+// apiHost maps docker hub to its registry api host
+func apiHost(ref imageref.Reference) string {
+	if ref.Registry() == imageref.DefaultRegistry {
+		return "registry-1.docker.io"
+	}
+	return ref.Registry()
+}
+
+func isLocalhost(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	return host == "localhost" || host == "127.0.0.1"
+}